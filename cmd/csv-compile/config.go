@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Outputs that can be generated, in the order they are written
+var outputs = []string{
+	"ships",
+	"pilot-duplicates",
+	"pilots",
+	"upgrades",
+	"pilot-performance",
+	"ship-performance",
+	"tournaments",
+	"players",
+	"matchups",
+	"pairings",
+	"top-pairings",
+	"ratings",
+	"rating-history",
+	"lists",
+	"archetypes",
+	"timeseries",
+	"top-lists",
+	"rejected-lists",
+	"data-quality",
+	"suggested-aliases",
+}
+
+// Formats outputs can be written in
+var outputformats = []string{
+	"csv",
+	"json",
+	"ndjson",
+}
+
+// Config controls a compile.  It can be read from a JSON file given by
+// -config, with any flags given on the command line taking precedence.
+type Config struct {
+	Exceptions string
+
+	// Overrides of the ship and pilot releases, none if empty
+	Releases string
+
+	Snapshots string
+	Snapshot string
+	SaveSnapshot string
+	Tournaments string
+	Cache string
+	Output string
+
+	Sources xwingdata.Sources
+
+	// Windows are placed relative to AsOf, by default the date of the
+	// latest tabulated tournament.  Recent tournaments are those in the
+	// RecentMonths up to it, unless an explicit RecentFrom and
+	// optionally RecentTo date are given.  Each of the extra Windows,
+	// given as name=months or name=date, adds its own uses.
+	AsOf string
+	RecentMonths int
+	RecentFrom string
+	RecentTo string
+	Windows []string
+
+	Formats []string
+	Scopes []string
+	Outputs []string
+	OutputFormats []string
+
+	// SQLite database and XLSX workbook written into the output
+	// folder, none if empty
+	SQLite string
+	Workbook string
+
+	Lenient bool
+	AutoAlias float64
+
+	// Partner pilots and upgrades listed per pilot in top-pairings, and
+	// squads listed in top-lists
+	PairingsTop int
+	TopLists int
+
+	// How lists are clustered into archetypes
+	ArchetypeSimilarity string
+	ArchetypeThreshold float64
+	ArchetypeUpgrades []string
+
+	// Period the time series and archetypes are bucketed by
+	Period string
+}
+
+func defaultconfig() *Config {
+	return &Config{
+		Exceptions: xwingdata.ExceptionsFile,
+		Releases: xwingdata.ReleasesFile,
+		Snapshots: fetch.SnapshotsFolder,
+		Tournaments: listjuggler.TournamentsFolder,
+		Cache: stats.CacheFile,
+		Output: ".",
+		Sources: xwingdata.DefaultSources,
+		RecentMonths: 4,
+		Formats: []string{stats.DogfightFormat},
+		Outputs: append([]string(nil), outputs...),
+		OutputFormats: []string{"csv"},
+		PairingsTop: 5,
+		TopLists: 50,
+		ArchetypeSimilarity: stats.DefaultSimilarity,
+		ArchetypeThreshold: stats.DefaultArchetypeThreshold,
+		Period: stats.DefaultPeriod,
+	}
+}
+
+func similarities() []string {
+	var names []string
+	for name := range(stats.Similarities) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func periods() []string {
+	var names []string
+	for name := range(stats.Periods) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listflag is a comma separated flag that replaces its list when set.
+type listflag struct {
+	list *[]string
+}
+
+func (l listflag) String() string {
+	if l.list == nil {
+		return ""
+	}
+	return strings.Join(*l.list, ",")
+}
+
+func (l listflag) Set(s string) error {
+	*l.list = nil
+	for _,x := range(strings.Split(s, ",")) {
+		if x = strings.TrimSpace(x); x != "" {
+			*l.list = append(*l.list, x)
+		}
+	}
+	return nil
+}
+
+func (c *Config) flags(configfile *string) *flag.FlagSet {
+
+	fs := flag.NewFlagSet("csv-compile", flag.ExitOnError)
+
+	fs.StringVar(configfile, "config", "", "JSON configuration file; flags override its settings")
+
+	fs.StringVar(&c.Exceptions, "exceptions", c.Exceptions, "Mapping file of data source exceptions")
+	fs.StringVar(&c.Releases, "releases", c.Releases, "File of ship and pilot release overrides, none if empty")
+	fs.StringVar(&c.Snapshots, "snapshots", c.Snapshots, "Folder containing X-Wing Data snapshots")
+	fs.StringVar(&c.Snapshot, "snapshot", c.Snapshot, "Compile from the named X-Wing Data snapshot instead of fetching")
+	fs.StringVar(&c.SaveSnapshot, "save-snapshot", c.SaveSnapshot, "Fetch X-Wing Data and save it as the named snapshot")
+	fs.StringVar(&c.Tournaments, "tournaments", c.Tournaments, "Folder of downloaded ListJuggler tournaments")
+	fs.StringVar(&c.Cache, "cache", c.Cache, "File caching processed tournaments between runs, none if empty")
+	fs.StringVar(&c.Output, "output", c.Output, "Folder to write outputs into")
+
+	fs.StringVar(&c.Sources.Ships, "ships-url", c.Sources.Ships, "X-Wing Data ships URL")
+	fs.StringVar(&c.Sources.Pilots, "pilots-url", c.Sources.Pilots, "X-Wing Data pilots URL")
+	fs.StringVar(&c.Sources.Upgrades, "upgrades-url", c.Sources.Upgrades, "X-Wing Data upgrades URL")
+	fs.StringVar(&c.Sources.Products, "products-url", c.Sources.Products, "X-Wing Data products URL, giving release dates, none if empty")
+
+	fs.StringVar(&c.AsOf, "as-of", c.AsOf, "Reference date the windows are placed against, YYYY-MM-DD, by default the latest tournament's")
+	fs.IntVar(&c.RecentMonths, "recent-months", c.RecentMonths, "Months up to the reference date that count as recent")
+	fs.StringVar(&c.RecentFrom, "recent-from", c.RecentFrom, "Start date of the recent window, YYYY-MM-DD, instead of -recent-months")
+	fs.StringVar(&c.RecentTo, "recent-to", c.RecentTo, "End date of the recent window, YYYY-MM-DD, exclusive")
+	fs.Var(listflag{&c.Windows}, "windows", "Comma separated extra windows with their own uses, each name=months, name=YYYY-MM-DD, or name=YYYY-MM-DD..YYYY-MM-DD")
+
+	fs.Var(listflag{&c.Formats}, "formats", "Comma separated tournament formats to include")
+	fs.Var(listflag{&c.Scopes}, "scopes", "Comma separated tournament scopes to include, all if empty")
+	fs.BoolVar(&c.Lenient, "lenient", c.Lenient, "Quarantine lists and tournaments that can't be resolved instead of stopping")
+	fs.Float64Var(&c.AutoAlias, "auto-alias", c.AutoAlias, "Apply suggested aliases for unknown pilots and ships scoring at least this, 0 to 1, never if 0")
+	fs.Var(listflag{&c.Outputs}, "outputs", "Comma separated outputs to generate: " + strings.Join(outputs, ","))
+	fs.StringVar(&c.SQLite, "sqlite", c.SQLite, "Also write a SQLite database of the compiled data, named this in the output folder")
+	fs.StringVar(&c.Workbook, "workbook", c.Workbook, "Also write an XLSX workbook of the main tables, named this in the output folder")
+	fs.IntVar(&c.PairingsTop, "pairings-top", c.PairingsTop, "Partner pilots and upgrades listed per pilot in top-pairings")
+	fs.StringVar(&c.ArchetypeSimilarity, "archetype-similarity", c.ArchetypeSimilarity, "Similarity measure clustering lists into archetypes: " + strings.Join(similarities(), ","))
+	fs.Float64Var(&c.ArchetypeThreshold, "archetype-threshold", c.ArchetypeThreshold, "Similarity from 0 to 1 at which a list joins an archetype")
+	fs.Var(listflag{&c.ArchetypeUpgrades}, "archetype-upgrades", "Comma separated upgrade XWS codes that distinguish archetypes")
+	fs.StringVar(&c.Period, "period", c.Period, "Period the time series and archetypes are bucketed by: " + strings.Join(periods(), ","))
+	fs.IntVar(&c.TopLists, "top-lists", c.TopLists, "Most played squads listed in top-lists")
+	fs.Var(listflag{&c.OutputFormats}, "output-formats", "Comma separated formats to write outputs in: " + strings.Join(outputformats, ","))
+
+	return fs
+
+}
+
+// loadconfig reads the defaults, then the config file if one is named
+// on the command line, then the flags again so they take precedence.
+func loadconfig(args []string, parent *logberry.Task) (*Config,error) {
+
+	task := parent.Task("Load configuration")
+
+	c := defaultconfig()
+
+	var configfile string
+	fs := c.flags(&configfile)
+	fs.Parse(args)
+
+	if configfile != "" {
+		bits, err := ioutil.ReadFile(configfile)
+		if err != nil {
+			return nil,task.Error(err)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(bits))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+		if err != nil {
+			return nil,task.WrapError("Could not unmarshal configuration", err, logberry.D{"File": configfile})
+		}
+
+		fs.Parse(args)
+	}
+
+	err := c.validate()
+	if err != nil {
+		return nil,task.WrapError("Invalid configuration", err)
+	}
+
+	return c,task.Success(logberry.D{"Config": c})
+
+}
+
+func (c *Config) validate() error {
+
+	if c.Snapshot != "" && c.SaveSnapshot != "" {
+		return fmt.Errorf("Cannot both load and save a snapshot")
+	}
+
+	if len(c.Formats) == 0 {
+		return fmt.Errorf("No tournament formats included")
+	}
+
+	for _,o := range(c.Outputs) {
+		if !xwingdata.Contains(outputs, o) {
+			return fmt.Errorf("Unknown output %v", o)
+		}
+	}
+
+	if len(c.OutputFormats) == 0 {
+		return fmt.Errorf("No output formats")
+	}
+
+	for _,f := range(c.OutputFormats) {
+		if !xwingdata.Contains(outputformats, f) {
+			return fmt.Errorf("Unknown output format %v", f)
+		}
+	}
+
+	if c.RecentFrom == "" && c.RecentMonths <= 0 {
+		return fmt.Errorf("Recent months %v must be positive", c.RecentMonths)
+	}
+
+	if c.AutoAlias < 0 || c.AutoAlias > 1 {
+		return fmt.Errorf("Auto alias threshold %v is not between 0 and 1", c.AutoAlias)
+	}
+
+	if _,ok := stats.Similarities[c.ArchetypeSimilarity]; !ok {
+		return fmt.Errorf("Unknown archetype similarity %v", c.ArchetypeSimilarity)
+	}
+
+	if c.ArchetypeThreshold < 0 || c.ArchetypeThreshold > 1 {
+		return fmt.Errorf("Archetype threshold %v is not between 0 and 1", c.ArchetypeThreshold)
+	}
+
+	if c.PairingsTop <= 0 {
+		return fmt.Errorf("Top pairings %v must be positive", c.PairingsTop)
+	}
+
+	if _,ok := stats.Periods[c.Period]; !ok {
+		return fmt.Errorf("Unknown period %v", c.Period)
+	}
+
+	if c.TopLists <= 0 {
+		return fmt.Errorf("Top lists %v must be positive", c.TopLists)
+	}
+
+	if _,err := c.options(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+func parsedate(s string) (time.Time,error) {
+	return time.Parse("2006-01-02", s)
+}
+
+// options converts the configuration into the stats package options.
+func (c *Config) options() (stats.Options,error) {
+
+	o := stats.Options{
+		Formats: c.Formats,
+		Scopes: c.Scopes,
+		Lenient: c.Lenient,
+		AutoAlias: c.AutoAlias,
+		ArchetypeSimilarity: c.ArchetypeSimilarity,
+		ArchetypeThreshold: c.ArchetypeThreshold,
+		ArchetypeUpgrades: c.ArchetypeUpgrades,
+		Period: c.Period,
+		Recent: stats.Window{Name: "Recent", Months: c.RecentMonths},
+	}
+
+	var err error
+	if c.AsOf != "" {
+		o.AsOf,err = parsedate(c.AsOf)
+		if err != nil {
+			return o,fmt.Errorf("Bad reference date %v", c.AsOf)
+		}
+	}
+
+	if c.RecentFrom != "" {
+		o.Recent.Months = 0
+		o.Recent.From,err = parsedate(c.RecentFrom)
+		if err != nil {
+			return o,fmt.Errorf("Bad recent start date %v", c.RecentFrom)
+		}
+	}
+
+	if c.RecentTo != "" {
+		o.Recent.To,err = parsedate(c.RecentTo)
+		if err != nil {
+			return o,fmt.Errorf("Bad recent end date %v", c.RecentTo)
+		}
+		if c.RecentFrom != "" && !o.Recent.To.After(o.Recent.From) {
+			return o,fmt.Errorf("Recent window ends %v, not after it starts %v", c.RecentTo, c.RecentFrom)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _,spec := range(c.Windows) {
+		w,err := stats.ParseWindow(spec)
+		if err != nil {
+			return o,err
+		}
+		if names[w.Name] {
+			return o,fmt.Errorf("Duplicate window %v", w.Name)
+		}
+		names[w.Name] = true
+		o.Windows = append(o.Windows, w)
+	}
+
+	return o,nil
+
+}
+
+func (c *Config) generates(output string) bool {
+	return xwingdata.Contains(c.Outputs, output)
+}
+
+func (c *Config) outputfile(name string) string {
+	return filepath.Join(c.Output, name)
+}
@@ -0,0 +1,287 @@
+// Command csv-compile compiles ship and pilot stats from X-Wing Data
+// and usage data from previously fetched ListJuggler tournaments into
+// CSV and JSON files, and optionally a SQLite database and workbook.
+package main
+
+import (
+	"os"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/csvout"
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/jsonout"
+	"github.com/RocketshipGames/xwing-csv/sqlout"
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xlsxout"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func main() {
+	ok := compile()
+	logberry.Std.Stop()
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// compile reads the data and tournaments and writes every configured
+// output, reporting whether it all succeeded.  An output that fails is
+// logged and the rest are still written.
+func compile() bool {
+
+	config,err := loadconfig(os.Args[1:], logberry.Main)
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	exceptions,err := xwingdata.LoadExceptions(config.Exceptions, logberry.Main)
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	var releases *xwingdata.ReleaseOverrides
+	if config.Releases != "" {
+		releases,err = xwingdata.LoadReleases(config.Releases, logberry.Main)
+		if err != nil {
+			logberry.Main.Error(err)
+			return false
+		}
+	}
+
+	var snapshot *fetch.Snapshot
+	if config.Snapshot != "" {
+		snapshot,err = fetch.LoadSnapshot(config.Snapshots, config.Snapshot, logberry.Main)
+	} else if config.SaveSnapshot != "" {
+		snapshot,err = fetch.NewSnapshot(config.Snapshots, config.SaveSnapshot, logberry.Main)
+	}
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	data,err := xwingdata.Load(config.Sources, exceptions, releases, snapshot, logberry.Main)
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	if snapshot != nil && snapshot.Saving() {
+		err = snapshot.WriteManifest(logberry.Main)
+		if err != nil {
+			logberry.Main.Error(err)
+			return false
+		}
+	}
+
+	options,err := config.options()
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	st := stats.New(data, options)
+
+	if config.Cache != "" {
+		fingerprint,err := stats.Fingerprint(data, options)
+		if err != nil {
+			logberry.Main.Error(err)
+			return false
+		}
+
+		st.Cache,err = stats.LoadCache(config.Cache, fingerprint, logberry.Main)
+		if err != nil {
+			logberry.Main.Error(err)
+			return false
+		}
+	}
+
+	err = st.ReadTournaments(config.Tournaments, logberry.Main)
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	err = st.Cache.Save(logberry.Main)
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	err = os.MkdirAll(config.Output, 0755)
+	if err != nil {
+		logberry.Main.WrapError("Could not create output folder", err, logberry.D{"Folder": config.Output})
+		return false
+	}
+
+	writers := map[string]func(string, *logberry.Task) error{
+		"ships": func(file string, task *logberry.Task) error {
+			return csvout.WriteShips(file, st, task)
+		},
+		"pilot-duplicates": func(file string, task *logberry.Task) error {
+			return csvout.WriteDuplicatePilots(file, data, task)
+		},
+		"pilots": func(file string, task *logberry.Task) error {
+			return csvout.WritePilots(file, st, task)
+		},
+		"upgrades": func(file string, task *logberry.Task) error {
+			return csvout.WriteUpgrades(file, st, task)
+		},
+		"pilot-performance": func(file string, task *logberry.Task) error {
+			return csvout.WritePilotPerformance(file, st, task)
+		},
+		"ship-performance": func(file string, task *logberry.Task) error {
+			return csvout.WriteShipPerformance(file, st, task)
+		},
+		"matchups": func(file string, task *logberry.Task) error {
+			return csvout.WriteMatchups(file, st, task)
+		},
+		"ratings": func(file string, task *logberry.Task) error {
+			return csvout.WriteRatings(file, st, task)
+		},
+		"rating-history": func(file string, task *logberry.Task) error {
+			return csvout.WriteRatingHistory(file, st, task)
+		},
+		"pairings": func(file string, task *logberry.Task) error {
+			return csvout.WritePairings(file, st, task)
+		},
+		"top-pairings": func(file string, task *logberry.Task) error {
+			return csvout.WriteTopPairings(file, st, config.PairingsTop, task)
+		},
+		"archetypes": func(file string, task *logberry.Task) error {
+			return csvout.WriteArchetypes(file, st, task)
+		},
+		"top-lists": func(file string, task *logberry.Task) error {
+			return csvout.WriteTopLists(file, st, config.TopLists, task)
+		},
+		"timeseries": func(file string, task *logberry.Task) error {
+			return csvout.WriteTimeSeries(file, st, task)
+		},
+		"tournaments": func(file string, task *logberry.Task) error {
+			return csvout.WriteTournaments(file, st, task)
+		},
+		"players": func(file string, task *logberry.Task) error {
+			return csvout.WritePlayers(file, st, task)
+		},
+		"lists": func(file string, task *logberry.Task) error {
+			return csvout.WriteLists(file, st, task)
+		},
+		"rejected-lists": func(file string, task *logberry.Task) error {
+			return csvout.WriteRejectedLists(file, st, task)
+		},
+		"data-quality": func(file string, task *logberry.Task) error {
+			return csvout.WriteDataQuality(file, st, task)
+		},
+		"suggested-aliases": func(file string, task *logberry.Task) error {
+			return csvout.WriteSuggestedAliases(file, st, task)
+		},
+	}
+
+	jsonwriters := map[string]func(string, bool, *logberry.Task) error{
+		"ships": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteShips(file, ndjson, st, task)
+		},
+		"pilot-duplicates": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteDuplicatePilots(file, ndjson, data, task)
+		},
+		"pilots": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WritePilots(file, ndjson, st, task)
+		},
+		"upgrades": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteUpgrades(file, ndjson, st, task)
+		},
+		"pilot-performance": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WritePilotPerformance(file, ndjson, st, task)
+		},
+		"ship-performance": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteShipPerformance(file, ndjson, st, task)
+		},
+		"matchups": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteMatchups(file, ndjson, st, task)
+		},
+		"ratings": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteRatings(file, ndjson, st, task)
+		},
+		"rating-history": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteRatingHistory(file, ndjson, st, task)
+		},
+		"pairings": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WritePairings(file, ndjson, st, task)
+		},
+		"top-pairings": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteTopPairings(file, ndjson, st, config.PairingsTop, task)
+		},
+		"archetypes": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteArchetypes(file, ndjson, st, task)
+		},
+		"top-lists": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteTopLists(file, ndjson, st, config.TopLists, task)
+		},
+		"timeseries": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteTimeSeries(file, ndjson, st, task)
+		},
+		"tournaments": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteTournaments(file, ndjson, st, task)
+		},
+		"players": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WritePlayers(file, ndjson, st, task)
+		},
+		"lists": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteLists(file, ndjson, st, task)
+		},
+		"rejected-lists": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteRejectedLists(file, ndjson, st, task)
+		},
+		"data-quality": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteDataQuality(file, ndjson, st, task)
+		},
+		"suggested-aliases": func(file string, ndjson bool, task *logberry.Task) error {
+			return jsonout.WriteSuggestedAliases(file, ndjson, st, task)
+		},
+	}
+
+	ok := true
+	written := func(err error) {
+		if err != nil {
+			logberry.Main.Error(err)
+			ok = false
+		}
+	}
+
+	for _,output := range(outputs) {
+		if !config.generates(output) {
+			continue
+		}
+		for _,format := range(config.OutputFormats) {
+			file := config.outputfile(output + "." + format)
+			if format == "csv" {
+				written(writers[output](file, logberry.Main))
+			} else {
+				written(jsonwriters[output](file, format == "ndjson", logberry.Main))
+			}
+		}
+	}
+
+	if config.SQLite != "" {
+		written(sqlout.WriteSQLite(config.outputfile(config.SQLite), st, logberry.Main))
+	}
+
+	if config.Workbook != "" {
+		written(xlsxout.WriteWorkbook(config.outputfile(config.Workbook), st, logberry.Main))
+	}
+
+	logberry.Main.Info("Counts", logberry.D{
+		"AsOf": st.AsOf.Format("2006-01-02"),
+		"AllTime": st.AllTime,
+		"Recent": st.Recent,
+	})
+
+	if len(st.UnknownUpgrades) > 0 {
+		logberry.Main.Warning("Unknown upgrades", logberry.D{"Counts": st.UnknownUpgrades})
+	}
+
+	return ok
+
+}
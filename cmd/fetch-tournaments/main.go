@@ -0,0 +1,358 @@
+// Command fetch-tournaments downloads every tournament report listed
+// by ListJuggler into the tournaments folder.  Reports already on disk
+// are only downloaded again if the server says they have changed, and
+// failed downloads are recorded so they can be retried later.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+)
+
+// The failed downloads and the index are kept in the tournaments
+// folder alongside the reports they describe.
+const ErrorsFile = "tournaments.errors"
+const IndexFile = "tournaments.index"
+
+// IndexInterval is how many tournaments are recorded between writes of
+// the index, so an interrupted run keeps most of what it gathered.
+const IndexInterval = 20
+
+// IndexEntry records what was last downloaded for a tournament.
+type IndexEntry struct {
+	fetch.Validators
+	SHA256 string
+}
+
+// Summary counts the outcome of each tournament.
+type Summary struct {
+	Listed int
+	Downloaded int
+	Changed int
+	Unchanged int
+	Skipped int
+	Failed int
+}
+
+type fetcher struct {
+	folder string
+	refresh bool
+	retry fetch.Retry
+
+	lock sync.Mutex
+	index map[string]IndexEntry
+	unsaved int
+	errlog *os.File
+	summary Summary
+}
+
+func main() {
+	ok := fetchall()
+	logberry.Std.Stop()
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// fetchall downloads the listed or previously failed tournaments,
+// reporting whether it could run.  Tournaments that fail to download
+// are recorded to be retried rather than failing the run.
+func fetchall() bool {
+
+	folder := flag.String("tournaments", listjuggler.TournamentsFolder, "Folder to download tournaments into")
+	workers := flag.Int("workers", 4, "Number of concurrent downloads")
+	attempts := flag.Int("attempts", fetch.DefaultRetry.Attempts, "Attempts per tournament before giving up on server errors")
+	backoff := flag.Duration("backoff", fetch.DefaultRetry.Backoff, "Wait before the first retry, doubled after each")
+	refresh := flag.Bool("refresh", false, "Re-download tournaments on disk the server can't say are unchanged")
+	retryfailed := flag.Bool("retry-failed", false, "Only retry the tournaments listed in " + ErrorsFile + " in the tournaments folder")
+	flag.Parse()
+
+	if *workers < 1 {
+		logberry.Main.Error(fmt.Errorf("Workers %v must be at least 1", *workers))
+		return false
+	}
+
+	// Retried tournaments are fetched again even if they're on disk
+	f := &fetcher{
+		folder: *folder,
+		refresh: *refresh || *retryfailed,
+		retry: fetch.Retry{Attempts: *attempts, Backoff: *backoff},
+	}
+
+	// Create directory for the results
+	err := os.MkdirAll(f.folder, 0755)
+	if err != nil {
+		logberry.Main.WrapError("Could not create tournaments folder", err, logberry.D{"Folder": f.folder})
+		return false
+	}
+
+	err = f.readindex()
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	// Determine which tournaments to fetch
+	var ids []int
+	if *retryfailed {
+		ids,err = f.readerrors()
+	} else {
+		ids,err = f.list()
+	}
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+	f.summary.Listed = len(ids)
+
+	f.errlog, err = os.Create(filepath.Join(f.folder, ErrorsFile))
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+	defer f.errlog.Close()
+
+	// Fetch all the tournaments with a bounded pool of workers
+	queue := make(chan int)
+	var wait sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for id := range(queue) {
+				f.get(id)
+			}
+		}()
+	}
+
+	for _,id := range(ids) {
+		queue <- id
+	}
+	close(queue)
+	wait.Wait()
+
+	err = f.writeindex()
+	if err != nil {
+		logberry.Main.Error(err)
+		return false
+	}
+
+	logberry.Main.Info("Summary", f.summary)
+	return true
+
+}
+
+func (f *fetcher) list() ([]int,error) {
+
+	var tournaments = struct {
+		Tournaments []int
+	}{}
+
+	// The list is retried like the tournaments, as one server error on
+	// it would otherwise stop the whole run
+	task := logberry.Main.Task("Get tournament list")
+	resp,err := fetch.GetConditional(listjuggler.TournamentListURL, fetch.Validators{}, f.retry, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	err = json.Unmarshal(resp.Body, &tournaments)
+	if err != nil {
+		return nil,task.WrapError("Could not unmarshal tournament list", err)
+	}
+
+	return tournaments.Tournaments,task.Success(logberry.D{"Count": len(tournaments.Tournaments)})
+
+}
+
+// readerrors reads the IDs of the tournaments that failed last time.
+func (f *fetcher) readerrors() ([]int,error) {
+
+	errorsfile := filepath.Join(f.folder, ErrorsFile)
+
+	task := logberry.Main.Task("Read failed tournaments", logberry.D{"File": errorsfile})
+
+	file, err := os.Open(errorsfile)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+	defer file.Close()
+
+	var ids []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(path.Base(line))
+		if err != nil {
+			return nil,task.WrapError("Bad tournament URL", err, logberry.D{"URL": line})
+		}
+		ids = append(ids, id)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil,task.Error(err)
+	}
+
+	return ids,task.Success(logberry.D{"Count": len(ids)})
+
+}
+
+func (f *fetcher) readindex() error {
+
+	f.index = make(map[string]IndexEntry)
+
+	bits, err := ioutil.ReadFile(filepath.Join(f.folder, IndexFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bits, &f.index)
+
+}
+
+// writeindex replaces the index file, writing it alongside first so
+// an interruption can't leave it half written.  The lock must be held
+// or the workers finished.
+func (f *fetcher) writeindex() error {
+
+	bits, err := json.MarshalIndent(f.index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(f.folder, IndexFile)
+	err = ioutil.WriteFile(file + ".tmp", bits, 0644)
+	if err != nil {
+		return err
+	}
+
+	f.unsaved = 0
+	return os.Rename(file + ".tmp", file)
+
+}
+
+// record indexes what was downloaded for a tournament and counts its
+// outcome, writing out the index every IndexInterval tournaments.
+func (f *fetcher) record(key string, entry IndexEntry, counter *int) {
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.index[key] = entry
+	*counter++
+
+	f.unsaved++
+	if f.unsaved >= IndexInterval {
+		if err := f.writeindex(); err != nil {
+			logberry.Main.Warning("Could not write index", err)
+		}
+	}
+
+}
+
+func (f *fetcher) count(counter *int) {
+	f.lock.Lock()
+	*counter++
+	f.lock.Unlock()
+}
+
+func (f *fetcher) get(id int) {
+
+	task := logberry.Main.Task("Get tournament", logberry.D{"ID": id})
+
+	key := strconv.Itoa(id)
+	url := listjuggler.TournamentURL(id)
+	dest := filepath.Join(f.folder, key + ".json")
+
+	f.lock.Lock()
+	entry,indexed := f.index[key]
+	f.lock.Unlock()
+
+	// Files on disk are only fetched again if the server can tell us
+	// whether they've changed, or a refresh was asked for
+	_,err := os.Stat(dest)
+	ondisk := err == nil
+	validators := fetch.Validators{}
+	if ondisk && indexed {
+		validators = entry.Validators
+	}
+	if ondisk && validators == (fetch.Validators{}) && !f.refresh {
+		f.count(&f.summary.Skipped)
+		task.Success(logberry.D{"Status": "Skipped"})
+		return
+	}
+
+	resp,err := fetch.GetConditional(url, validators, f.retry, task)
+	if err != nil {
+		f.lock.Lock()
+		fmt.Fprintln(f.errlog, url)
+		f.summary.Failed++
+		f.lock.Unlock()
+		task.Error(err)
+		return
+	}
+
+	if resp.NotModified() {
+		f.count(&f.summary.Unchanged)
+		task.Success(logberry.D{"Status": "Unchanged"})
+		return
+	}
+
+	// Compare against what's on disk in case the server doesn't
+	// support conditional requests
+	hash := fetch.ContentHash(resp.Body)
+	if ondisk {
+		previous := entry.SHA256
+		if !indexed {
+			if bits,err := ioutil.ReadFile(dest); err == nil {
+				previous = fetch.ContentHash(bits)
+			}
+		}
+
+		if previous == hash {
+			f.record(key, IndexEntry{Validators: resp.Validators, SHA256: hash}, &f.summary.Unchanged)
+			task.Success(logberry.D{"Status": "Unchanged"})
+			return
+		}
+	}
+
+	err = ioutil.WriteFile(dest, resp.Body, 0644)
+	if err != nil {
+		f.lock.Lock()
+		fmt.Fprintln(f.errlog, url)
+		f.summary.Failed++
+		f.lock.Unlock()
+		task.WrapError("Could not write body", err)
+		return
+	}
+
+	counter := &f.summary.Downloaded
+	if ondisk {
+		counter = &f.summary.Changed
+	}
+	f.record(key, IndexEntry{Validators: resp.Validators, SHA256: hash}, counter)
+
+	task.Success(logberry.D{"Status": "Downloaded"})
+
+}
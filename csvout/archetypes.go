@@ -0,0 +1,75 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var ArchetypeColumns = []string{
+	"Archetype",
+	"Faction",
+	"Leader",
+	"Variants",
+	"Period",
+	"Lists",
+	"Share %",
+	"Average Percentile",
+	"Top Cut Rate %",
+}
+
+// ArchetypeRows gives each archetype's all time and recent usage and
+// performance, and then its periods in order.
+func ArchetypeRows(rows Rows, st *stats.Stats) error {
+
+	for _,a := range(st.Archetypes().List) {
+
+		periods := []*stats.ArchetypePeriod{&a.AllTime, &a.Recent}
+		periods = append(periods, a.Periods...)
+
+		for _,p := range(periods) {
+
+			var average, topcuts interface{} = "", ""
+			if p.Ranked > 0 {
+				average = p.AveragePercentile()
+			}
+			if p.Lists > 0 {
+				topcuts = p.TopCutRate()
+			}
+
+			err := rows.Write(
+				a.Name,
+				a.Faction,
+				a.Leader,
+				a.Variants,
+				p.Period,
+				p.Lists,
+				p.Share(),
+				average,
+				topcuts)
+			if err != nil {
+				return err
+			}
+
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteArchetypes(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write archetypes", logberry.D{"File": file})
+
+	n, err := writetable(file, ArchetypeColumns, func(rows Rows) error {
+		return ArchetypeRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Archetypes": len(st.Archetypes().List), "Rows": n})
+
+}
@@ -0,0 +1,165 @@
+package csvout
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"testing"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+//
+// The archived ships and pilots are rebuilt from the X-Wing Data and
+// usage counts they record, and written again through the table rows
+// csv-compile uses, which must reproduce every archived record.  The
+// archived lists can't be rebuilt without their tournament reports, so
+// their records are written back through a table and must read back
+// the same.
+//
+
+func atoi(t *testing.T, s string) int {
+	if s == "" {
+		return 0
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("Archived %q is not a number", s)
+	}
+	return i
+}
+
+// archiveduses reads the uses columns of an archived pilot from i on.
+func archiveduses(t *testing.T, record []string, i int) stats.Uses {
+	return stats.Uses{
+		Total: atoi(t, record[i]),
+		Worlds: atoi(t, record[i+1]),
+		Nationals: atoi(t, record[i+2]),
+		Regionals: atoi(t, record[i+3]),
+		Stores: atoi(t, record[i+4]),
+		Vassals: atoi(t, record[i+5]),
+		Other: atoi(t, record[i+6]),
+	}
+}
+
+// archivestats rebuilds the data and counts the archived ships and
+// pilots were written from.
+func archivestats(t *testing.T) *stats.Stats {
+
+	data := &xwingdata.Data{Exceptions: &xwingdata.Exceptions{}}
+	ships := make(map[string]*xwingdata.Ship)
+
+	for _,r := range(readcsv(t, archive + "ships.csv")[1:]) {
+		ship := &xwingdata.Ship{
+			Name: r[0],
+			Size: r[4],
+			Attack: atoi(t, r[5]),
+			Agility: atoi(t, r[6]),
+			Hull: atoi(t, r[7]),
+			Shields: atoi(t, r[8]),
+			XWS: r[9+len(Actions)],
+		}
+		for _,faction := range(r[1:4]) {
+			if faction != "" {
+				ship.Faction = append(ship.Faction, faction)
+			}
+		}
+		for _,action := range(r[9:9+len(Actions)]) {
+			if action != "" {
+				ship.Actions = append(ship.Actions, action)
+			}
+		}
+		data.Ships = append(data.Ships, ship)
+		ships[ship.Name] = ship
+	}
+
+	st := stats.New(data, stats.DefaultOptions())
+
+	for _,r := range(readcsv(t, archive + "pilots.csv")[1:]) {
+		pilot := &xwingdata.Pilot{
+			Name: r[0],
+			XWS: r[1],
+			Faction: r[2],
+			Ship: r[3],
+			Unique: r[4] != "",
+			Points: xwingdata.Int(atoi(t, r[6])),
+			Skill: xwingdata.Int(atoi(t, r[7])),
+			Chassis: ships[r[3]],
+		}
+		if pilot.Chassis == nil {
+			t.Fatalf("Archived pilot %v flies unknown ship %v", pilot.Name, pilot.Ship)
+		}
+		for i,slot := range(Slots) {
+			for n := atoi(t, r[12+i]); n > 0; n-- {
+				pilot.Slots = append(pilot.Slots, slot)
+			}
+		}
+		data.Pilots = append(data.Pilots, pilot)
+
+		uses := 12 + len(Slots)
+		counts := st.Pilot(pilot)
+		counts.AllTime = archiveduses(t, r, uses)
+		counts.Recent = archiveduses(t, r, uses + 7)
+	}
+
+	return st
+
+}
+
+// writerows writes a table to memory and reads its records back.
+func writerows(t *testing.T, columns []string, write func(Rows) error) [][]string {
+
+	var b bytes.Buffer
+	table, err := NewTable(&b, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = write(table); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("Table is not valid CSV: %v", err)
+	}
+	return records
+
+}
+
+func TestArchiveBodies(t *testing.T) {
+
+	st := archivestats(t)
+
+	cases := []struct {
+		file string
+		columns []string
+		write func(Rows) error
+	}{
+		{"ships.csv", ShipColumns, func(rows Rows) error { return ShipRows(rows, st) }},
+		{"pilots.csv", PilotColumns, func(rows Rows) error { return PilotRows(rows, st) }},
+	}
+
+	for _,c := range(cases) {
+		t.Run(c.file, func(t *testing.T) {
+			comparecsv(t, writerows(t, c.columns, c.write), readcsv(t, archive + c.file))
+		})
+	}
+
+	t.Run("lists.csv", func(t *testing.T) {
+		archived := readcsv(t, archive + "lists.csv")
+		records := writerows(t, archived[0], func(rows Rows) error {
+			for _,r := range(archived[1:]) {
+				if err := rows.Write(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		comparecsv(t, records, archived)
+	})
+
+}
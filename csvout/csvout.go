@@ -0,0 +1,100 @@
+// Package csvout writes the compiled X-Wing Data and ListJuggler
+// statistics as CSV files.
+package csvout
+
+
+var Actions = []string {
+	"Focus",
+	"Target Lock",
+	"Barrel Roll",
+	"Evade",
+	"Boost",
+	"Cloak",
+	"SLAM",
+	"Rotate Arc",
+	//// Huge ships
+	// "Coordinate",
+	// "Jam",
+	// "Recover",
+	// "Reinforce",
+}
+
+var Slots = []string{
+	"Elite",
+	"Astromech",
+	"Salvaged Astromech",
+	"Crew",
+	"System",
+	"Tech",
+	"Turret",
+	"Torpedo",
+	"Missile",
+	"Cannon",
+	"Bomb",
+	"Illicit",
+	//// Huge ships
+	// "Cargo",
+	// "Hardpoint",
+	// "Team",
+	//// Ubiquitous
+	// "Title",
+	// "Modification",
+}
+
+type Flags map[string]int
+
+func (f Flags) Check(flag string) string {
+	if _,ok := f[flag]; ok {
+		return flag
+	}
+	return ""
+}
+
+func (f Flags) Count(flag string) int {
+	return f[flag]	
+}
+
+func (f Flags) Add(flag string) {
+	c := f[flag]
+	f[flag] = c+1	
+}
+
+func NewFlags(list []string) Flags {
+	m := make(Flags)
+	for _,s := range(list) {
+		m.Add(s)
+	}
+	return m
+
+}
+
+func keycheck(flags Flags, keys []string) []interface{} {
+	var fields []interface{}
+	for _,k := range(keys) {
+		fields = append(fields, flags.Check(k))
+	}
+	return fields
+}
+
+func keycount(flags Flags, keys []string) []interface{} {
+	var fields []interface{}
+	for _,k := range(keys) {
+		fields = append(fields, flags.Count(k))
+	}
+	return fields
+}
+
+// ifset leaves unknown numbers, given as zero, empty.
+func ifset(v int) interface{} {
+	if v == 0 {
+		return ""
+	}
+	return v
+}
+
+func ifbool(v bool, field string) string {
+	if v {
+		return field
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package csvout
+
+import (
+	"encoding/csv"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+//
+// The golden files in testdata/golden are the outputs compiled from the
+// X-Wing Data snapshot and tournament reports alongside them.  Each
+// test compiles those inputs as csv-compile does and must reproduce its
+// golden file field for field.  After an intended change to an output,
+// review and regenerate them with
+//
+//    go test ./csvout -run Golden -update
+//
+// The archived outputs in archives/ predate the snapshots, so they
+// can't be compiled again.  Their headers are checked against the
+// golden headers: each must still be written exactly, as the first
+// columns, so the archives and new outputs can be read by the same
+// positions.  Their bodies are checked in archive_test.go.
+//
+
+var update = flag.Bool("update", false, "Rewrite the golden outputs from the testdata inputs")
+
+const archive = "../archives/20160212-"
+
+func readcsv(t *testing.T, file string) [][]string {
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("%v is not valid CSV: %v", file, err)
+	}
+
+	return records
+
+}
+
+// comparecsv checks that records match expected field for field.
+func comparecsv(t *testing.T, records [][]string, expected [][]string) {
+
+	if len(records) != len(expected) {
+		t.Fatalf("Wrote %v records, expected %v", len(records), len(expected))
+	}
+	for i := range(expected) {
+		if len(records[i]) != len(expected[i]) {
+			t.Fatalf("Record %v has %v fields, expected %v", i, len(records[i]), len(expected[i]))
+		}
+		for j := range(expected[i]) {
+			if records[i][j] != expected[i][j] {
+				t.Errorf("Record %v %v is %q, expected %q", i, expected[0][j], records[i][j], expected[i][j])
+			}
+		}
+	}
+
+}
+
+// compile tabulates the testdata tournaments against its snapshot.
+func compile(t *testing.T) *stats.Stats {
+
+	exceptions, err := xwingdata.LoadExceptions(filepath.Join("testdata", xwingdata.ExceptionsFile), logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := fetch.LoadSnapshot(filepath.Join("testdata", "snapshots"), "test", logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := xwingdata.Load(xwingdata.DefaultSources, exceptions, nil, snapshot, logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := stats.DefaultOptions()
+	options.Lenient = true
+
+	st := stats.New(data, options)
+	err = st.ReadTournaments(filepath.Join("testdata", "tournaments"), logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return st
+
+}
+
+func TestGolden(t *testing.T) {
+
+	st := compile(t)
+
+	cases := []struct {
+		file string
+		write func(string, *stats.Stats, *logberry.Task) error
+	}{
+		{"ships.csv", WriteShips},
+		{"pilots.csv", WritePilots},
+		{"upgrades.csv", WriteUpgrades},
+		{"lists.csv", WriteLists},
+	}
+
+	for _,c := range(cases) {
+		t.Run(c.file, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), c.file)
+			if err := c.write(file, st, logberry.Main); err != nil {
+				t.Fatal(err)
+			}
+
+			written, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "golden", c.file)
+			if *update {
+				if err = ioutil.WriteFile(golden, written, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			comparecsv(t, readcsv(t, file), readcsv(t, golden))
+
+		})
+	}
+
+}
+
+func TestArchiveColumns(t *testing.T) {
+
+	for _,file := range([]string{"ships.csv", "pilots.csv", "lists.csv"}) {
+		archived := readcsv(t, archive + file)[0]
+		written := readcsv(t, filepath.Join("testdata", "golden", file))[0]
+
+		if len(written) < len(archived) {
+			t.Errorf("Writing %v columns of %v, expected at least the %v archived", len(written), file, len(archived))
+			continue
+		}
+		for i,column := range(archived) {
+			if written[i] != column {
+				t.Errorf("%v column %v is %q, archived as %q", file, i, written[i], column)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,191 @@
+package csvout
+
+import (
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+// percentile is a list's percentile, empty if it has no placement.
+func percentile(list *stats.ListInstance) interface{} {
+	if p,ok := list.Percentile(); ok {
+		return p
+	}
+	return ""
+}
+
+// ListColumns start with the columns of the archived lists.csv, in
+// the same order, so it can still be read by position; later columns
+// follow them.
+var ListColumns = []string{
+	"Date",
+	"Scope",
+	"Country",
+	"State",
+	"# Players",
+	"Rank",
+	"Faction",
+	"Ship Points",
+	"# Ships",
+	"# Uniques",
+	"# Large",
+	"# Small",
+	"Skill",
+	"Attack",
+	"Agility",
+	"Hull",
+	"Shields",
+	"List",
+	"Elimination Rank",
+	"Top Cut",
+	"Percentile",
+	"Player",
+	"Player ID",
+	"Archetype",
+	"Upgrade Points",
+	"Total Points",
+	"List ID",
+}
+
+func ListRows(rows Rows, st *stats.Stats, task *logberry.Task) error {
+
+	archetypes := st.Archetypes()
+
+	for _,list := range(st.Lists) {
+
+		liststats,err := stats.NewListStats(st.Data, list.List, task)
+		if err != nil {
+			return err
+		}
+		
+		err = rows.Write(
+			list.EventDate,
+			list.EventScope,
+			list.EventCountry,
+			list.EventState,
+			list.EventPlayers,
+			list.EventRank,
+			list.List.Faction,
+			liststats.SumShipPoints,
+			liststats.NumShips,
+			liststats.NumUniques,
+			liststats.NumLarge,
+			liststats.NumSmall,
+			liststats.SumSkill,
+			liststats.SumAttack,
+			liststats.SumAgility,
+			liststats.SumHull,
+			liststats.SumShields,
+			liststats.Text,
+			ifset(list.EventElimination),
+			ifbool(list.TopCut(), "top cut"),
+			percentile(list),
+			list.PlayerName,
+			ifset(list.PlayerID),
+			archetypes.Name(list),
+			liststats.SumUpgradePoints,
+			liststats.SumTotalPoints,
+			list.ID())
+		if err != nil {
+			return err
+		}
+
+	}
+	
+	return nil
+
+}
+
+func WriteLists(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write list stats", logberry.D{"File": file})
+
+	n, err := writetable(file, ListColumns, func(rows Rows) error {
+		return ListRows(rows, st, task)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+func resolved(list *listjuggler.List) bool {
+	for _,pilotinstance := range(list.Pilots) {
+		if pilotinstance.Pilot == nil {
+			return false
+		}
+	}
+	return true
+}
+
+var RejectedColumns = []string{
+	"Tournament",
+	"Date",
+	"Scope",
+	"Faction",
+	"Reason",
+	"Ship Points",
+	"Upgrade Points",
+	"Total Points",
+	"List",
+}
+
+func RejectedRows(rows Rows, st *stats.Stats, task *logberry.Task) error {
+
+	for _,rejected := range(st.RejectedLists) {
+
+		// Lists that couldn't be resolved are described by their XWS
+		ships, upgrades, text := 0, 0, ""
+		if !resolved(rejected.List) {
+			var names []string
+			for _,pilotinstance := range(rejected.List.Pilots) {
+				names = append(names, pilotinstance.XWS)
+			}
+			text = strings.Join(names, ", ")
+		} else {
+			liststats,err := stats.NewListStats(st.Data, rejected.List, task)
+			if err != nil {
+				return err
+			}
+			ships, upgrades, text = liststats.SumShipPoints, liststats.SumUpgradePoints, liststats.Text
+		}
+
+		err := rows.Write(
+			stats.TournamentID(rejected.File),
+			rejected.EventDate,
+			rejected.EventScope,
+			rejected.List.Faction,
+			rejected.Reason,
+			ships,
+			upgrades,
+			ships+upgrades,
+			text)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteRejectedLists(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write rejected lists", logberry.D{"File": file})
+
+	n, err := writetable(file, RejectedColumns, func(rows Rows) error {
+		return RejectedRows(rows, st, task)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rejected": n})
+
+}
@@ -0,0 +1,56 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var MatchupColumns = []string{
+	"Kind",
+	"Side",
+	"Opponent",
+	"Games",
+	"Wins",
+	"Losses",
+	"Draws",
+	"Win Rate %",
+}
+
+func MatchupRows(rows Rows, st *stats.Stats) error {
+
+	for _,m := range(st.Matchups()) {
+
+		err := rows.Write(
+			m.Kind,
+			m.Side,
+			m.Opponent,
+			m.Games,
+			m.Wins,
+			m.Losses,
+			m.Draws,
+			m.WinRate())
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteMatchups(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write matchups", logberry.D{"File": file})
+
+	n, err := writetable(file, MatchupColumns, func(rows Rows) error {
+		return MatchupRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n, "Matches": len(st.Matches)})
+
+}
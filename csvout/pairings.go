@@ -0,0 +1,160 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// partner names the other pilot or the upgrade in a pairing.
+func partner(data *xwingdata.Data, p *stats.Pairing) (string,string) {
+	if p.Kind == stats.PairingPilot {
+		return data.PilotLabel(p.Partner),p.Partner.XWS
+	}
+	return p.Upgrade.Name,p.Upgrade.XWS
+}
+
+var PairingColumns = []string{
+	"Kind",
+	"Pilot",
+	"Pilot XWS",
+	"Faction",
+	"Ship",
+	"Partner",
+	"Partner XWS",
+	"Lists",
+	"Pilot Lists",
+	"Partner Lists",
+	"Share %",
+	"Lift",
+	"Average Percentile",
+}
+
+func PairingRows(rows Rows, st *stats.Stats) error {
+
+	for _,p := range(st.Pairings().Pairs) {
+
+		faction,err := xwingdata.FactionMap(p.Pilot.Faction)
+		if err != nil {
+			return err
+		}
+
+		name,xws := partner(st.Data, p)
+
+		err = rows.Write(
+			p.Kind,
+			st.Data.PilotLabel(p.Pilot),
+			p.Pilot.XWS,
+			faction,
+			p.Pilot.Ship,
+			name,
+			xws,
+			p.Lists,
+			p.PilotLists,
+			p.PartnerLists,
+			p.Share(),
+			p.Lift(),
+			ifranked(p.Ranked, p.AveragePercentile()))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WritePairings(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write pairings", logberry.D{"File": file})
+
+	n, err := writetable(file, PairingColumns, func(rows Rows) error {
+		return PairingRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+var TopPairingColumns = []string{
+	"Pilot",
+	"Pilot XWS",
+	"Faction",
+	"Ship",
+	"Kind",
+	"Rank",
+	"Partner",
+	"Partner XWS",
+	"Lists",
+	"Share %",
+	"Lift",
+	"Average Percentile",
+}
+
+// TopPairingRows gives each pilot's n most common partner pilots and
+// upgrades.
+func TopPairingRows(rows Rows, st *stats.Stats, n int) error {
+
+	pairings := st.Pairings()
+
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			return err
+		}
+
+		for _,kind := range([]string{stats.PairingPilot, stats.PairingUpgrade}) {
+			for rank,p := range(pairings.Partners(pilot, kind, n)) {
+
+				name,xws := partner(st.Data, p)
+
+				err = rows.Write(
+					st.Data.PilotLabel(pilot),
+					pilot.XWS,
+					faction,
+					pilot.Ship,
+					kind,
+					rank+1,
+					name,
+					xws,
+					p.Lists,
+					p.Share(),
+					p.Lift(),
+					ifranked(p.Ranked, p.AveragePercentile()))
+				if err != nil {
+					return err
+				}
+
+			}
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteTopPairings(file string, st *stats.Stats, n int, parent *logberry.Task) error {
+
+	task := parent.Task("Write top pairings", logberry.D{"File": file, "Top": n})
+
+	rows, err := writetable(file, TopPairingColumns, func(rows Rows) error {
+		return TopPairingRows(rows, st, n)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": rows})
+
+}
@@ -0,0 +1,148 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+var performancecolumns = []string{
+	"# All Time Lists",
+	"All Time Average Percentile",
+	"All Time Top Cut Rate %",
+	"All Time Performance Index",
+	"# Recent Lists",
+	"Recent Average Percentile",
+	"Recent Top Cut Rate %",
+	"Recent Performance Index",
+}
+
+func performance(p *stats.Performance, expected *stats.Performance) []interface{} {
+	if p.Lists == 0 {
+		return []interface{}{0, "", "", ""}
+	}
+	if p.Ranked == 0 {
+		return []interface{}{p.Lists, "", p.TopCutRate(), ""}
+	}
+	return []interface{}{
+		p.Lists,
+		p.AveragePercentile(),
+		p.TopCutRate(),
+		p.Index(expected),
+	}
+}
+
+// ifranked gives an average percentile, empty when there are no
+// ranked lists to average.
+func ifranked(ranked int, average float64) interface{} {
+	if ranked == 0 {
+		return ""
+	}
+	return average
+}
+
+func performancedata(p *stats.Performances, st *stats.Stats) []interface{} {
+	return append(
+		performance(&p.AllTime, &st.Performance.AllTime),
+		performance(&p.Recent, &st.Performance.Recent)...)
+}
+
+var PilotPerformanceColumns = columns(
+	[]string{
+		"Name",
+		"XWS",
+		"Faction",
+		"Ship",
+	},
+	performancecolumns,
+)
+
+func PilotPerformanceRows(rows Rows, st *stats.Stats) error {
+
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			return err
+		}
+
+		err = rows.Write(
+			pilot.Name,
+			pilot.XWS,
+			faction,
+			pilot.Ship,
+			performancedata(st.PilotPerformance(pilot), st))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WritePilotPerformance(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write pilot performance", logberry.D{"File": file})
+
+	n, err := writetable(file, PilotPerformanceColumns, func(rows Rows) error {
+		return PilotPerformanceRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+var ShipPerformanceColumns = columns(
+	[]string{
+		"Name",
+		"XWS",
+	},
+	performancecolumns,
+)
+
+func ShipPerformanceRows(rows Rows, st *stats.Stats) error {
+
+	for _,ship := range(st.Data.Ships) {
+
+		if xwingdata.Contains(st.Data.Exceptions.ExcludedSizes, ship.Size) {
+			continue
+		}
+
+		err := rows.Write(
+			ship.Name,
+			ship.XWS,
+			performancedata(st.ShipPerformance(ship), st))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteShipPerformance(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write ship performance", logberry.D{"File": file})
+
+	n, err := writetable(file, ShipPerformanceColumns, func(rows Rows) error {
+		return ShipPerformanceRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
@@ -0,0 +1,389 @@
+package csvout
+
+import (
+	"sort"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+var ShipColumns = columns(
+	[]string{
+		"Name",
+		"Rebel",
+		"Imperial",
+		"Scum",
+		"Size",
+		"Attack",
+		"Agility",
+		"Hull",
+		"Shields",
+	},
+	Actions,
+	[]string{"XWS"},
+)
+
+// ReleaseColumns give when a ship or pilot became available and in
+// what product.  They follow the fixed ship and pilot columns if any
+// releases are known.
+var ReleaseColumns = []string{
+	"Available Since",
+	"Product",
+}
+
+func releasedata(data *xwingdata.Data, r xwingdata.Release) []interface{} {
+	if !data.HasReleases() {
+		return nil
+	}
+	return []interface{}{r.Date, r.Product}
+}
+
+// AllShipColumns gives the ship columns along with the release and
+// eligibility columns if any releases are known.
+func AllShipColumns(st *stats.Stats) []string {
+	if !st.Data.HasReleases() {
+		return ShipColumns
+	}
+	return columns(ShipColumns, ReleaseColumns, EligibleColumns)
+}
+
+func ShipRows(rows Rows, st *stats.Stats) error {
+
+	for _,ship := range(st.Data.Ships) {
+
+		sfactions,err := ship.Factions()
+		if err != nil {
+			return err
+		}
+		factions := NewFlags(sfactions)
+
+		sactions := NewFlags(ship.Actions)
+		
+		err = rows.Write(
+			ship.Name,
+			factions.Check("rebel"),
+			factions.Check("imperial"),
+			factions.Check("scum"),
+			ship.Size,
+			ship.Attack,
+			ship.Agility,
+			ship.Hull,
+			ship.Shields,
+			keycheck(sactions,Actions),
+			ship.XWS,
+			releasedata(st.Data, ship.Release),
+			eligibledata(st, st.ShipEligibility(ship)))
+		if err != nil {
+			return err
+		}
+	}
+	
+	return nil
+
+}
+
+func WriteShips(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write ship stats", logberry.D{"File": file})
+
+	n, err := writetable(file, AllShipColumns(st), func(rows Rows) error {
+		return ShipRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+// DuplicateRows lists each pilot name shared by another pilot, one row
+// per name followed by the XWS, ship, and ship XWS of each pilot
+// sharing it.  The rows vary in length, so the table has no header.
+func DuplicateRows(rows Rows, data *xwingdata.Data) error {
+
+	var names []string
+	for k,l := range(data.PilotNames) {
+		if len(l) > 1 {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	for _,k := range(names) {
+		row := []interface{}{k}
+		for _,p := range(data.PilotNames[k]) {
+			row = append(row, p.XWS, p.Chassis.Name, p.Chassis.XWS)
+		}
+
+		err := rows.Write(row...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+func WriteDuplicatePilots(file string, data *xwingdata.Data, parent *logberry.Task) error {
+
+	task := parent.Task("Write duplicate pilots", logberry.D{"File": file})
+
+	n, err := writetable(file, nil, func(rows Rows) error {
+		return DuplicateRows(rows, data)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+var usescolumns = []string{
+	"Total All Time Uses",
+	"World Championship All Time Uses",
+	"Nationals All Time Uses",
+	"Regional All Time Uses",
+	"Store Championship All Time Uses",
+	"Vassal All Time Uses",
+	"Other All Time Uses",
+	"Total Recent Uses",
+	"World Championship Recent Uses",
+	"Nationals Recent Uses",
+	"Regional Recent Uses",
+	"Store Championship Recent Uses",
+	"Vassal Recent Uses",
+	"Other Recent Uses",
+}
+
+// WindowColumns names the uses columns added for each of the extra
+// windows, which come last in the pilot and upgrade tables.
+func WindowColumns(st *stats.Stats) []string {
+	var cols []string
+	for _,w := range(st.Options.Windows) {
+		for _,scope := range([]string{"Total", "World Championship", "Nationals", "Regional", "Store Championship", "Vassal", "Other"}) {
+			cols = append(cols, scope + " " + w.Name + " Uses")
+		}
+	}
+	return cols
+}
+
+// EligibleColumns count a pilot's or ship's use among the lists of its
+// factions since its release, following the release columns.
+var EligibleColumns = []string{
+	"Lists Since Available",
+	"Eligible Lists",
+	"Eligible Share %",
+	"Recent Lists Since Available",
+	"Recent Eligible Lists",
+	"Recent Eligible Share %",
+}
+
+func eligibledata(st *stats.Stats, e *stats.Eligibility) []interface{} {
+	if !st.Data.HasReleases() {
+		return nil
+	}
+	return []interface{}{
+		e.AllTime.Lists,
+		e.AllTime.Eligible,
+		e.AllTime.Share(),
+		e.Recent.Lists,
+		e.Recent.Eligible,
+		e.Recent.Share(),
+	}
+}
+
+// AllPilotColumns gives the pilot columns along with the release and
+// eligibility columns if any releases are known, and those of each
+// extra window.
+func AllPilotColumns(st *stats.Stats) []string {
+	if !st.Data.HasReleases() {
+		return columns(PilotColumns, WindowColumns(st))
+	}
+	return columns(PilotColumns, ReleaseColumns, EligibleColumns, WindowColumns(st))
+}
+
+// AllUpgradeColumns gives the upgrade columns along with those of each
+// extra window.
+func AllUpgradeColumns(st *stats.Stats) []string {
+	return columns(UpgradeColumns, WindowColumns(st))
+}
+
+func usesdata(c *stats.Counts) []interface{} {
+	return []interface{}{
+		c.AllTime.Total,
+		c.AllTime.Worlds,
+		c.AllTime.Nationals,
+		c.AllTime.Regionals,
+		c.AllTime.Stores,
+		c.AllTime.Vassals,
+		c.AllTime.Other,			
+		c.Recent.Total,
+		c.Recent.Worlds,
+		c.Recent.Nationals,
+		c.Recent.Regionals,
+		c.Recent.Stores,
+		c.Recent.Vassals,
+		c.Recent.Other,			
+	}
+}
+
+func windowsdata(st *stats.Stats, c *stats.Counts) []interface{} {
+	var data []interface{}
+	for i := range(st.Options.Windows) {
+		w := c.Window(i)
+		data = append(data, w.Total, w.Worlds, w.Nationals, w.Regionals, w.Stores, w.Vassals, w.Other)
+	}
+	return data
+}
+
+var PilotColumns = columns(
+	[]string{
+		"Name",
+		"XWS",
+		"Faction",
+		"Ship",
+		"Unique",
+		"Size",
+		"Points",
+		"Skill",
+		"Attack",
+		"Agility",
+		"Hull",
+		"Shields",
+	},
+	Slots,
+	usescolumns,
+)
+
+func PilotRows(rows Rows, st *stats.Stats) error {
+	
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+		
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			return err
+		}
+
+		pslots := NewFlags(pilot.Slots)
+
+		err = rows.Write(
+			pilot.Name,
+			pilot.XWS,
+			faction,
+			pilot.Ship,
+			ifbool(pilot.Unique, "unique"),
+			pilot.Chassis.Size,
+			pilot.Points,
+			pilot.Skill,
+			pilot.Chassis.Attack,
+			pilot.Chassis.Agility,
+			pilot.Chassis.Hull,
+			pilot.Chassis.Shields,			
+			keycount(pslots,Slots),
+			usesdata(st.Pilot(pilot)),
+			releasedata(st.Data, pilot.Release),
+			eligibledata(st, st.PilotEligibility(pilot)),
+			windowsdata(st, st.Pilot(pilot)))
+		if err != nil {
+			return err
+		}
+
+	}
+		
+	return nil
+
+}
+
+func WritePilots(file string, st *stats.Stats, parent *logberry.Task) error {
+	
+	task := parent.Task("Write pilot stats", logberry.D{"File": file})
+
+	n, err := writetable(file, AllPilotColumns(st), func(rows Rows) error {
+		return PilotRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+var UpgradeColumns = columns(
+	[]string{
+		"Name",
+		"XWS",
+		"Slot",
+		"Points",
+		"Unique",
+		"Limited",
+		"Faction",
+	},
+	usescolumns,
+)
+
+func UpgradeRows(rows Rows, st *stats.Stats) error {
+	
+	var err error
+	for _,upgrade := range(st.Data.Upgrades) {
+
+		if st.Data.Exceptions.ExcludedUpgrade(upgrade) {
+			continue
+		}
+
+		// The unreachable side of a dual-sided card
+		if upgrade.Code == "" {
+			continue
+		}
+
+		faction := ""
+		if upgrade.Faction != "" {
+			faction,err = xwingdata.FactionMap(upgrade.Faction)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = rows.Write(
+			upgrade.Name,
+			upgrade.XWS,
+			upgrade.Slot,
+			upgrade.Points,
+			ifbool(upgrade.Unique, "unique"),
+			ifbool(upgrade.Limited, "limited"),
+			faction,
+			usesdata(st.Upgrade(upgrade)),
+			windowsdata(st, st.Upgrade(upgrade)))
+		if err != nil {
+			return err
+		}
+
+	}
+		
+	return nil
+
+}
+
+func WriteUpgrades(file string, st *stats.Stats, parent *logberry.Task) error {
+	
+	task := parent.Task("Write upgrade stats", logberry.D{"File": file})
+	
+	n, err := writetable(file, AllUpgradeColumns(st), func(rows Rows) error {
+		return UpgradeRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
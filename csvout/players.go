@@ -0,0 +1,62 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var PlayerColumns = []string{
+	"Player",
+	"# Events",
+	"First Event",
+	"Last Event",
+	"# Top Cuts",
+	"Rebel",
+	"Imperial",
+	"Scum",
+	"Average Percentile",
+	"# Recent Events",
+	"Recent Average Percentile",
+}
+
+func PlayerRows(rows Rows, st *stats.Stats) error {
+
+	for _,p := range(st.Players()) {
+
+		err := rows.Write(
+			p.Name,
+			p.Events,
+			p.FirstEvent,
+			p.LastEvent,
+			p.TopCuts,
+			p.Factions["rebel"],
+			p.Factions["imperial"],
+			p.Factions["scum"],
+			p.AveragePercentile,
+			p.RecentEvents,
+			p.RecentAveragePercentile)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WritePlayers(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write players", logberry.D{"File": file})
+
+	n, err := writetable(file, PlayerColumns, func(rows Rows) error {
+		return PlayerRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
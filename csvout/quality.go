@@ -0,0 +1,52 @@
+package csvout
+
+import (
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var QualityColumns = []string{
+	"Issue",
+	"Detail",
+	"Count",
+	"# Tournaments",
+	"Tournaments",
+}
+
+func QualityRows(rows Rows, st *stats.Stats) error {
+
+	for _,summary := range(st.SummarizeIssues()) {
+
+		err := rows.Write(
+			summary.Kind,
+			summary.Detail,
+			summary.Count,
+			len(summary.Tournaments),
+			strings.Join(summary.Tournaments, " "))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteDataQuality(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write data quality report", logberry.D{"File": file})
+
+	n, err := writetable(file, QualityColumns, func(rows Rows) error {
+		return QualityRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Issues": n})
+
+}
@@ -0,0 +1,113 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var RatingColumns = []string{
+	"Kind",
+	"Name",
+	"Last Period",
+	"Games",
+	"Wins",
+	"Losses",
+	"Draws",
+	"Rating",
+	"RD",
+	"Volatility",
+}
+
+var RatingHistoryColumns = []string{
+	"Period",
+	"Kind",
+	"Name",
+	"Games",
+	"Wins",
+	"Losses",
+	"Draws",
+	"Rating",
+	"RD",
+	"Volatility",
+}
+
+func RatingRows(rows Rows, st *stats.Stats) error {
+
+	for _,r := range(st.Ratings().Current) {
+
+		err := rows.Write(
+			r.Kind,
+			r.Name,
+			r.Period,
+			r.Games,
+			r.Wins,
+			r.Losses,
+			r.Draws,
+			r.Rating,
+			r.RD,
+			r.Volatility)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func RatingHistoryRows(rows Rows, st *stats.Stats) error {
+
+	for _,r := range(st.Ratings().History) {
+
+		err := rows.Write(
+			r.Period,
+			r.Kind,
+			r.Name,
+			r.Games,
+			r.Wins,
+			r.Losses,
+			r.Draws,
+			r.Rating,
+			r.RD,
+			r.Volatility)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteRatings(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write ratings", logberry.D{"File": file})
+
+	n, err := writetable(file, RatingColumns, func(rows Rows) error {
+		return RatingRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
+
+func WriteRatingHistory(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write rating history", logberry.D{"File": file})
+
+	n, err := writetable(file, RatingHistoryColumns, func(rows Rows) error {
+		return RatingHistoryRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
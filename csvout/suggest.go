@@ -0,0 +1,72 @@
+package csvout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var SuggestionColumns = []string{
+	"Exception",
+	"Unknown",
+	"From",
+	"To",
+	"Score",
+	"Count",
+	"Applied",
+	"Candidates",
+}
+
+// SuggestionRows gives the candidate aliases for unknown pilots and
+// ships, each row giving the exceptions entry that would resolve it by
+// its best candidate.
+func SuggestionRows(rows Rows, st *stats.Stats) error {
+
+	for _,suggestion := range(st.SortedSuggestions()) {
+
+		score := ""
+		if len(suggestion.Candidates) > 0 {
+			score = fmt.Sprintf("%.2f", suggestion.Candidates[0].Score)
+		}
+
+		var candidates []string
+		for _,c := range(suggestion.Candidates) {
+			candidates = append(candidates, fmt.Sprintf("%v %.2f", c.Code, c.Score))
+		}
+
+		err := rows.Write(
+			suggestion.Exception,
+			suggestion.Code,
+			suggestion.From,
+			suggestion.Best(),
+			score,
+			suggestion.Count,
+			ifbool(suggestion.Applied, "Applied"),
+			strings.Join(candidates, "; "))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteSuggestedAliases(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write suggested aliases", logberry.D{"File": file})
+
+	n, err := writetable(file, SuggestionColumns, func(rows Rows) error {
+		return SuggestionRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Suggestions": n})
+
+}
@@ -0,0 +1,150 @@
+package csvout
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+//
+// Every output is written as a Table, which quotes fields and ends
+// records with CRLF per RFC 4180 through encoding/csv.  Each file declares its columns up front, and
+// rows that don't match them are an error rather than a shifted
+// spreadsheet.  The rows themselves are produced separately, for any
+// Rows, so other formats can be built from the same tables.
+//
+
+// Rows receives the rows of a table.
+type Rows interface {
+	Write(values ...interface{}) error
+}
+
+type Table struct {
+	Columns []string
+	Rows int
+
+	w *csv.Writer
+	closer io.Closer
+}
+
+// columns concatenates groups of column names into one schema.
+func columns(groups ...[]string) []string {
+	var list []string
+	for _,g := range(groups) {
+		list = append(list, g...)
+	}
+	return list
+}
+
+// NewTable starts a table on w, writing the header row.  A table with
+// no columns has no header and takes rows of any length.
+func NewTable(w io.Writer, columns []string) (*Table,error) {
+
+	t := &Table{
+		Columns: columns,
+		w: csv.NewWriter(w),
+	}
+	t.w.UseCRLF = true
+
+	if columns != nil {
+		err := t.w.Write(columns)
+		if err != nil {
+			return nil,err
+		}
+	}
+
+	return t,nil
+
+}
+
+// CreateTable creates file and starts a table in it.
+func CreateTable(file string, columns []string) (*Table,error) {
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil,err
+	}
+
+	t, err := NewTable(f, columns)
+	if err != nil {
+		f.Close()
+		return nil,err
+	}
+	t.closer = f
+
+	return t,nil
+
+}
+
+// Flatten expands slices of values in a row, so groups of columns can
+// be filled from one helper.
+func Flatten(values ...interface{}) []interface{} {
+	row := make([]interface{}, 0, len(values))
+	for _,v := range(values) {
+		switch x := v.(type) {
+		case []interface{}:
+			row = append(row, x...)
+		case []string:
+			for _,y := range(x) {
+				row = append(row, y)
+			}
+		default:
+			row = append(row, x)
+		}
+	}
+	return row
+}
+
+// Write adds a row, flattening it.
+func (t *Table) Write(values ...interface{}) error {
+
+	row := make([]string, 0, len(t.Columns))
+	for _,v := range(Flatten(values...)) {
+		row = append(row, fmt.Sprint(v))
+	}
+
+	if t.Columns != nil && len(row) != len(t.Columns) {
+		return fmt.Errorf("Row has %v fields but the table has %v columns", len(row), len(t.Columns))
+	}
+
+	t.Rows++
+	return t.w.Write(row)
+
+}
+
+// Close flushes the table, returning any error from writing it, and
+// closes its file if it created one.
+func (t *Table) Close() error {
+
+	t.w.Flush()
+	err := t.w.Error()
+
+	if t.closer != nil {
+		if cerr := t.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+
+}
+
+// writetable writes a whole table to file, returning how many rows it
+// has.
+func writetable(file string, columns []string, rows func(Rows) error) (int,error) {
+
+	t, err := CreateTable(file, columns)
+	if err != nil {
+		return 0,err
+	}
+
+	err = rows(t)
+	if err != nil {
+		t.Close()
+		return 0,err
+	}
+
+	return t.Rows,t.Close()
+
+}
@@ -0,0 +1,131 @@
+package csvout
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestTableQuoting(t *testing.T) {
+
+	cases := []struct {
+		field string
+		encoded string
+	}{
+		{"plain", "plain"},
+		{"Target Lock", "Target Lock"},
+		{"Han Solo, Chewbacca", `"Han Solo, Chewbacca"`},
+		{`"Whisper"`, `"""Whisper"""`},
+		{"two\nlines", "\"two\r\nlines\""},
+		{"", ""},
+	}
+
+	for _,c := range(cases) {
+		var b bytes.Buffer
+		table, err := NewTable(&b, []string{"A", "B"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = table.Write(c.field, "x"); err != nil {
+			t.Fatal(err)
+		}
+		if err = table.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Records end in CRLF, as do line breaks within fields
+		expected := "A,B\r\n" + c.encoded + ",x\r\n"
+		if b.String() != expected {
+			t.Errorf("Field %q encoded as %q, expected %q", c.field, b.String(), expected)
+		}
+	}
+
+}
+
+func TestTableRoundTrip(t *testing.T) {
+
+	rows := [][]string{
+		{"Name", "# Players", "List"},
+		{`"Fel's Wrath"`, "12", "Soontir Fel, \"Howlrunner\"\r\nAcademy Pilot"},
+		{"", "0", " leading and trailing "},
+	}
+
+	var b bytes.Buffer
+	table, err := NewTable(&b, rows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _,row := range(rows[1:]) {
+		if err = table.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := csv.NewReader(&b)
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Quoted CRLFs are read back as LFs
+	rows[1][2] = "Soontir Fel, \"Howlrunner\"\nAcademy Pilot"
+	if !reflect.DeepEqual(records, rows) {
+		t.Errorf("Read back %q, expected %q", records, rows)
+	}
+
+}
+
+func TestTableSchema(t *testing.T) {
+
+	var b bytes.Buffer
+	table, err := NewTable(&b, []string{"A", "B", "C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = table.Write("a", "b"); err == nil {
+		t.Error("Short row was written")
+	}
+
+	if err = table.Write("a", []interface{}{"b", 1, 2}); err == nil {
+		t.Error("Long row was written")
+	}
+
+	if err = table.Write("a", []interface{}{"b", 3}); err != nil {
+		t.Error(err)
+	}
+
+	if table.Rows != 1 {
+		t.Errorf("Table has %v rows, expected 1", table.Rows)
+	}
+
+}
+
+func TestTableHeaderless(t *testing.T) {
+
+	var b bytes.Buffer
+	table, err := NewTable(&b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = table.Write("Biggs Darklighter", "biggsdarklighter", "X-Wing", "xwing"); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.Write("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err = table.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Biggs Darklighter,biggsdarklighter,X-Wing,xwing\r\na,b\r\n"
+	if b.String() != expected {
+		t.Errorf("Table written as %q, expected %q", b.String(), expected)
+	}
+
+}
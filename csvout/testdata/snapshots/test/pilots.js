@@ -0,0 +1,10 @@
+[
+ {"name":"Wedge Antilles","unique":true,"ship":"X-Wing","skill":9,"points":29,"slots":["Elite","Torpedo","Astromech"],"faction":"Rebel Alliance","xws":"wedgeantilles","id":0},
+ {"name":"Rookie Pilot","ship":"X-Wing","skill":2,"points":21,"slots":["Torpedo","Astromech"],"faction":"Rebel Alliance","xws":"rookiepilot","id":1},
+ {"name":"Academy Pilot","ship":"TIE Fighter","skill":1,"points":12,"slots":[],"faction":"Galactic Empire","xws":"academypilot","id":2},
+ {"name":"\"Howlrunner\"","unique":true,"ship":"TIE Fighter","skill":8,"points":18,"slots":["Elite"],"faction":"Galactic Empire","xws":"howlrunner","id":3},
+ {"name":"Han Solo","unique":true,"ship":"YT-1300","skill":9,"points":46,"slots":["Elite","Missile","Crew","Crew"],"faction":"Rebel Alliance","xws":"hansolo","id":4},
+ {"name":"Outer Rim Smuggler","ship":"YT-1300","skill":1,"points":27,"slots":["Crew","Crew"],"faction":"Rebel Alliance","xws":"outerrimsmuggler","id":5},
+ {"name":"Black Sun Ace","ship":"Kihraxz Fighter","skill":5,"points":23,"slots":["Elite","Missile","Illicit"],"faction":"Scum and Villainy","xws":"blacksunace","id":6},
+ {"name":"Sabine Wren","unique":true,"ship":"TIE Fighter","skill":5,"points":15,"slots":["Elite"],"faction":"Rebel Alliance","xws":"sabinewren","id":7}
+]
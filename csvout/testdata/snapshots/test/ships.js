@@ -0,0 +1,6 @@
+[
+ {"name":"X-Wing","faction":["Rebel Alliance"],"attack":3,"agility":2,"hull":3,"shields":2,"actions":["Focus","Target Lock"],"size":"small","xws":"xwing","id":1},
+ {"name":"TIE Fighter","faction":["Galactic Empire","Rebel Alliance"],"attack":2,"agility":3,"hull":3,"shields":0,"actions":["Focus","Barrel Roll","Evade"],"size":"small","xws":"tiefighter","id":2},
+ {"name":"YT-1300","faction":["Rebel Alliance"],"attack":3,"agility":1,"hull":8,"shields":5,"actions":["Focus","Target Lock"],"size":"large","xws":"yt1300","id":3},
+ {"name":"Kihraxz Fighter","faction":["Scum and Villainy"],"attack":3,"agility":2,"hull":4,"shields":1,"actions":["Focus","Target Lock"],"size":"small","xws":"kihraxzfighter","id":4}
+]
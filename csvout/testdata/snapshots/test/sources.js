@@ -0,0 +1,7 @@
+[
+ {"name":"Core Set","id":1,"released":true,"release_date":"2012-09-14","contents":{"ships":{"1":1,"2":2},"pilots":{"0":1,"1":1,"2":2,"3":1},"upgrades":{}}},
+ {"name":"Millennium Falcon Expansion Pack","id":2,"released":true,"release_date":"2013-02-28","contents":{"ships":{"3":1},"pilots":{"4":1,"5":1},"upgrades":{}}},
+ {"name":"Kihraxz Fighter Expansion Pack","id":3,"released":true,"release_date":"2016-06-09","contents":{"ships":{"4":1},"pilots":{"6":1},"upgrades":{}}},
+ {"name":"Sabine's TIE Fighter Expansion Pack","id":4,"released":true,"release_date":"2016-12-01","contents":{"ships":{"2":1},"pilots":{"7":1},"upgrades":{}}},
+ {"name":"Unreleased Pack","id":5,"released":false,"contents":{"ships":{},"pilots":{"7":1},"upgrades":{}}}
+]
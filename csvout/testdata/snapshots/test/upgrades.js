@@ -0,0 +1,10 @@
+[
+ {"name":"Push the Limit","slot":"Elite","points":3,"xws":"pushthelimit"},
+ {"name":"R2-D2","slot":"Astromech","points":4,"unique":true,"xws":"r2d2"},
+ {"name":"Proton Torpedoes","slot":"Torpedo","points":4,"xws":"protontorpedoes"},
+ {"name":"Vaksai","slot":"Title","points":0,"xws":"vaksai","ship":["Kihraxz Fighter"]},
+ {"name":"Concussion Missiles","slot":"Missile","points":4,"xws":"concussionmissiles"},
+ {"name":"Inertial Dampeners","slot":"Illicit","points":1,"xws":"inertialdampeners"},
+ {"name":"Chewbacca","slot":"Crew","points":4,"unique":true,"faction":"Rebel Alliance","xws":"chewbacca"},
+ {"name":"Veteran Instincts","slot":"Elite","points":1,"xws":"veteraninstincts"}
+]
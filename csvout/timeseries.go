@@ -0,0 +1,56 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var TimeSeriesColumns = []string{
+	"Period",
+	"Kind",
+	"Name",
+	"XWS",
+	"Lists",
+	"Copies",
+	"Share %",
+	"Period Lists",
+}
+
+func TimeSeriesRows(rows Rows, st *stats.Stats) error {
+
+	for _,p := range(st.TimeSeries()) {
+
+		err := rows.Write(
+			p.Period,
+			p.Kind,
+			p.Name,
+			p.XWS,
+			p.Lists,
+			p.Copies,
+			p.Share(),
+			p.TotalLists)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteTimeSeries(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write time series", logberry.D{"File": file, "Period": st.Options.Period})
+
+	n, err := writetable(file, TimeSeriesColumns, func(rows Rows) error {
+		return TimeSeriesRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
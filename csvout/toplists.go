@@ -0,0 +1,89 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var TopListColumns = []string{
+	"Rank",
+	"List ID",
+	"Variant Of",
+	"Difference",
+	"Faction",
+	"Lists",
+	"Players",
+	"Average Percentile",
+	"Top Cut Rate %",
+	"First Date",
+	"Last Date",
+	"List",
+}
+
+// TopListRows gives the n most played squads flown more than once,
+// each followed by its variants.
+func TopListRows(rows Rows, st *stats.Stats, n int) error {
+
+	for rank,e := range(st.ExactLists()) {
+
+		if rank >= n || e.Lists < 2 {
+			break
+		}
+
+		err := rows.Write(
+			rank+1,
+			e.ID,
+			"",
+			"",
+			e.Faction,
+			e.Lists,
+			e.Players,
+			ifranked(e.Ranked, e.AveragePercentile()),
+			e.TopCutRate(),
+			e.FirstDate,
+			e.LastDate,
+			e.Text)
+		if err != nil {
+			return err
+		}
+
+		for _,v := range(e.Variants) {
+			err = rows.Write(
+				rank+1,
+				v.ID,
+				e.ID,
+				v.Difference,
+				v.Faction,
+				v.Lists,
+				v.Players,
+				ifranked(v.Ranked, v.AveragePercentile()),
+				v.TopCutRate(),
+				v.FirstDate,
+				v.LastDate,
+				v.Text)
+			if err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteTopLists(file string, st *stats.Stats, n int, parent *logberry.Task) error {
+
+	task := parent.Task("Write top lists", logberry.D{"File": file, "Top": n})
+
+	rows, err := writetable(file, TopListColumns, func(rows Rows) error {
+		return TopListRows(rows, st, n)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": rows})
+
+}
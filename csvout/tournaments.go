@@ -0,0 +1,74 @@
+package csvout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+var TournamentColumns = []string{
+	"ID",
+	"Name",
+	"Date",
+	"Scope",
+	"Format",
+	"Venue",
+	"City",
+	"State",
+	"Country",
+	"Declared Players",
+	"Reported Players",
+	"Round Length",
+	"# Lists",
+	"List Coverage %",
+	"Status",
+	"Reason",
+}
+
+// TournamentRows gives every tournament report, whether its lists were
+// tabulated, and if not why not.
+func TournamentRows(rows Rows, st *stats.Stats) error {
+
+	for _,t := range(st.Tournaments) {
+
+		err := rows.Write(
+			t.ID,
+			t.Name,
+			t.Date,
+			t.Scope,
+			t.Format,
+			t.Venue,
+			t.City,
+			t.State,
+			t.Country,
+			t.DeclaredPlayers,
+			t.ReportedPlayers,
+			t.RoundLength,
+			t.Lists,
+			t.Coverage(),
+			t.Status,
+			t.Reason)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+func WriteTournaments(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write tournaments", logberry.D{"File": file})
+
+	n, err := writetable(file, TournamentColumns, func(rows Rows) error {
+		return TournamentRows(rows, st)
+	})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": n})
+
+}
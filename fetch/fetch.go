@@ -0,0 +1,68 @@
+// Package fetch retrieves the remote data sources, optionally reading
+// from or recording into a local snapshot.
+package fetch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+
+	"github.com/BellerophonMobile/logberry"
+)
+
+func GetBody(url string, parent *logberry.Task) ([]byte,error) {
+
+	task := parent.Task("Get body", logberry.D{"URL": url})
+
+	resp,err := http.Get(url)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil,task.WrapError("Could not read body", err)
+	}
+	
+	if resp.StatusCode != 200 {
+		return nil,task.Failure("Server error", logberry.D{"Status": resp.StatusCode, "Response": string(body)})
+	}
+
+	return body,task.Success()
+
+}
+
+// GetAsJSON retrieves url and unmarshals it into dest.  If snapshot is
+// being loaded the body is read from it instead of the network, and
+// if it is being saved the fetched body is recorded into it.  A nil
+// snapshot always goes to the network.
+func GetAsJSON(dest interface{}, url string, snapshot *Snapshot, parent *logberry.Task) error {
+
+	task := parent.Task("Get as JSON", logberry.D{"URL": url, "Type": fmt.Sprintf("%T", dest)})
+
+	var body []byte
+	var err error
+
+	if snapshot != nil && !snapshot.saving {
+		body,err = snapshot.Read(path.Base(url), task)
+	} else {
+		body,err = GetBody(url, task)
+		if err == nil && snapshot != nil {
+			err = snapshot.Write(path.Base(url), url, body, task)
+		}
+	}
+	if err != nil {
+		return task.Error(err)
+	}
+
+	err = json.Unmarshal(body, dest)
+	if err != nil {
+		return task.WrapError("Could unmarshal body", err)
+	}
+	
+	return task.Success()
+
+}
@@ -0,0 +1,116 @@
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/BellerophonMobile/logberry"
+)
+
+// Retry controls how requests failing with network or server (5xx)
+// errors, or rate limited (429), are retried.  The wait doubles after
+// each attempt.
+type Retry struct {
+	Attempts int
+	Backoff time.Duration
+}
+
+var DefaultRetry = Retry{
+	Attempts: 4,
+	Backoff: 2 * time.Second,
+}
+
+// Validators are the HTTP cache validators returned with a resource,
+// used to ask the server whether it has changed since.
+type Validators struct {
+	ETag string `json:",omitempty"`
+	LastModified string `json:",omitempty"`
+}
+
+type Response struct {
+	Status int
+	Body []byte
+	Validators Validators
+}
+
+func (r *Response) NotModified() bool {
+	return r.Status == http.StatusNotModified
+}
+
+func get(url string, validators Validators) (*Response,error) {
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil,err
+	}
+
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil,err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil,err
+	}
+
+	return &Response{
+		Status: resp.StatusCode,
+		Body: body,
+		Validators: Validators{
+			ETag: resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	},nil
+
+}
+
+// retryable reports whether a request failed in a way worth trying
+// again: the network or server failed, or asked us to slow down.
+func retryable(resp *Response, err error) bool {
+	return err != nil || resp.Status >= 500 || resp.Status == http.StatusTooManyRequests
+}
+
+// GetConditional retrieves url, retrying network and server errors and
+// rate limiting.  If validators are given the server may answer that
+// the resource is unchanged, in which case the response is NotModified
+// and has no body.
+func GetConditional(url string, validators Validators, retry Retry, parent *logberry.Task) (*Response,error) {
+
+	task := parent.Task("Get conditional", logberry.D{"URL": url})
+
+	wait := retry.Backoff
+	for attempt := 1; ; attempt++ {
+
+		resp, err := get(url, validators)
+
+		if !retryable(resp, err) {
+			if resp.Status != http.StatusOK && !resp.NotModified() {
+				return nil,task.Failure("Client error", logberry.D{"Status": resp.Status, "Response": string(resp.Body)})
+			}
+			return resp,task.Success(logberry.D{"Status": resp.Status, "Attempts": attempt})
+		}
+
+		if attempt >= retry.Attempts {
+			if err != nil {
+				return nil,task.Error(err, logberry.D{"Attempts": attempt})
+			}
+			return nil,task.Failure("Server error", logberry.D{"Status": resp.Status, "Response": string(resp.Body), "Attempts": attempt})
+		}
+
+		task.Warning("Retrying", logberry.D{"Attempt": attempt, "Wait": wait.String()})
+		time.Sleep(wait)
+		wait *= 2
+
+	}
+
+}
@@ -0,0 +1,167 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BellerophonMobile/logberry"
+)
+
+//
+// Snapshots store the fetched X-Wing Data files in a local folder
+// along with a manifest recording when they were fetched and their
+// content hashes, so that a compile can be reproduced later or run
+// without a network connection.
+//
+
+const SnapshotsFolder = "snapshots/"
+const SnapshotManifest = "manifest.json"
+
+type SnapshotFile struct {
+	Name string
+	URL string
+	SHA256 string
+}
+
+type Snapshot struct {
+	Name string
+	Fetched string
+	Files []*SnapshotFile
+	folder string
+	saving bool
+}
+
+func snapshotfolder(folder string, name string) string {
+	return filepath.Join(folder, name)
+}
+
+// NewSnapshot starts a snapshot to be filled in by GetAsJSON and then
+// saved with WriteManifest.
+func NewSnapshot(folder string, name string, parent *logberry.Task) (*Snapshot,error) {
+
+	task := parent.Task("Create snapshot", logberry.D{"Folder": folder, "Name": name})
+
+	err := os.MkdirAll(snapshotfolder(folder, name), 0755)
+	if err != nil {
+		return nil,task.WrapError("Could not create snapshot folder", err)
+	}
+
+	s := &Snapshot{
+		Name: name,
+		Fetched: time.Now().UTC().Format(time.RFC3339),
+		folder: folder,
+		saving: true,
+	}
+	
+	return s,task.Success()
+
+}
+
+func LoadSnapshot(folder string, name string, parent *logberry.Task) (*Snapshot,error) {
+
+	task := parent.Task("Load snapshot", logberry.D{"Folder": folder, "Name": name})
+
+	bits, err := ioutil.ReadFile(filepath.Join(snapshotfolder(folder, name), SnapshotManifest))
+	if err != nil {
+		return nil,task.WrapError("Could not read snapshot manifest", err)
+	}
+
+	var s Snapshot
+	err = json.Unmarshal(bits, &s)
+	if err != nil {
+		return nil,task.WrapError("Could not unmarshal snapshot manifest", err)
+	}
+	s.folder = folder
+
+	return &s,task.Success(logberry.D{"Fetched": s.Fetched, "Files": len(s.Files)})
+
+}
+
+func (s *Snapshot) Saving() bool {
+	return s.saving
+}
+
+// Has reports whether the snapshot recorded a file, since older
+// snapshots may predate a source being fetched.
+func (s *Snapshot) Has(file string) bool {
+	for _,f := range(s.Files) {
+		if f.Name == file {
+			return true
+		}
+	}
+	return false
+}
+
+func ContentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Snapshot) Read(file string, parent *logberry.Task) ([]byte,error) {
+
+	task := parent.Task("Read snapshot file", logberry.D{"Snapshot": s.Name, "File": file})
+
+	var entry *SnapshotFile
+	for _,f := range(s.Files) {
+		if f.Name == file {
+			entry = f
+		}
+	}
+	if entry == nil {
+		return nil,task.Failure("File not in snapshot manifest")
+	}
+
+	body, err := ioutil.ReadFile(filepath.Join(snapshotfolder(s.folder, s.Name), file))
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	if hash := ContentHash(body); hash != entry.SHA256 {
+		return nil,task.Failure("Snapshot file hash mismatch", logberry.D{"Expected": entry.SHA256, "Actual": hash})
+	}
+
+	return body,task.Success()
+
+}
+
+func (s *Snapshot) Write(file string, url string, body []byte, parent *logberry.Task) error {
+
+	task := parent.Task("Write snapshot file", logberry.D{"Snapshot": s.Name, "File": file})
+
+	err := ioutil.WriteFile(filepath.Join(snapshotfolder(s.folder, s.Name), file), body, 0644)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	s.Files = append(s.Files, &SnapshotFile{
+		Name: file,
+		URL: url,
+		SHA256: ContentHash(body),
+	})
+
+	return task.Success()
+
+}
+
+func (s *Snapshot) WriteManifest(parent *logberry.Task) error {
+
+	task := parent.Task("Write snapshot manifest", logberry.D{"Snapshot": s.Name})
+
+	bits, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return task.Error(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(snapshotfolder(s.folder, s.Name), SnapshotManifest), bits, 0644)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success()
+
+}
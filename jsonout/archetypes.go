@@ -0,0 +1,78 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+type ArchetypePeriod struct {
+	Period string
+	Lists int
+	Share float64
+	RankedLists int
+	AveragePercentile float64
+	TopCutRate float64
+}
+
+type Archetype struct {
+	Name string
+	Faction string
+	Leader string
+	Variants int
+	AllTime ArchetypePeriod
+	Recent ArchetypePeriod
+	Periods []ArchetypePeriod
+}
+
+func archetypeperiod(p *stats.ArchetypePeriod) ArchetypePeriod {
+	return ArchetypePeriod{
+		Period: p.Period,
+		Lists: p.Lists,
+		Share: p.Share(),
+		RankedLists: p.Ranked,
+		AveragePercentile: p.AveragePercentile(),
+		TopCutRate: p.TopCutRate(),
+	}
+}
+
+func WriteArchetypes(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write archetypes", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,a := range(st.Archetypes().List) {
+
+		r := &Archetype{
+			Name: a.Name,
+			Faction: a.Faction,
+			Leader: a.Leader,
+			Variants: a.Variants,
+			AllTime: archetypeperiod(&a.AllTime),
+			Recent: archetypeperiod(&a.Recent),
+			Periods: []ArchetypePeriod{},
+		}
+		for _,p := range(a.Periods) {
+			r.Periods = append(r.Periods, archetypeperiod(p))
+		}
+
+		err = s.Write(r)
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
@@ -0,0 +1,234 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+type ListUpgrade struct {
+	Name string
+	XWS string
+	Slot string
+	Points int
+}
+
+// ListPilot is one pilot in a list.  Pilots that couldn't be resolved
+// against X-Wing Data only have the XWS and ship given in the report.
+type ListPilot struct {
+	Name string `json:",omitempty"`
+	XWS string
+	Ship string
+	Points int
+	Upgrades []ListUpgrade
+}
+
+// percentile is a list's percentile, nil if it has no placement.
+func percentile(list *stats.ListInstance) *float64 {
+	if p,ok := list.Percentile(); ok {
+		return &p
+	}
+	return nil
+}
+
+func listpilots(data *xwingdata.Data, list *listjuggler.List) []ListPilot {
+
+	pilots := []ListPilot{}
+	for _,pilotinstance := range(list.Pilots) {
+
+		pilot := pilotinstance.Pilot
+		if pilot == nil {
+			pilots = append(pilots, ListPilot{
+				XWS: pilotinstance.XWS,
+				Ship: pilotinstance.Ship,
+				Upgrades: []ListUpgrade{},
+			})
+			continue
+		}
+
+		p := ListPilot{
+			Name: data.PilotLabel(pilot),
+			XWS: pilot.XWS,
+			Ship: pilot.Chassis.XWS,
+			Points: int(pilot.Points),
+			Upgrades: []ListUpgrade{},
+		}
+		for _,upgrade := range(pilotinstance.UpgradeCards) {
+			p.Upgrades = append(p.Upgrades, ListUpgrade{
+				Name: upgrade.Name,
+				XWS: upgrade.XWS,
+				Slot: upgrade.Slot,
+				Points: int(upgrade.Points),
+			})
+		}
+		pilots = append(pilots, p)
+
+	}
+
+	return pilots
+
+}
+
+type List struct {
+	Date string
+	Scope string
+	Country string
+	State string
+	Players int
+	Rank int
+	EliminationRank int `json:",omitempty"`
+	TopCut bool
+	Percentile *float64 `json:",omitempty"`
+	Player string `json:",omitempty"`
+	PlayerID int `json:",omitempty"`
+	Faction string
+	Archetype string
+
+	ShipPoints int
+	UpgradePoints int
+	TotalPoints int
+
+	Ships int
+	Uniques int
+	Large int
+	Small int
+
+	Skill int
+	Attack int
+	Agility int
+	Hull int
+	Shields int
+
+	ListID string
+	Pilots []ListPilot
+}
+
+func WriteLists(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write list stats", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	archetypes := st.Archetypes()
+
+	for _,list := range(st.Lists) {
+
+		liststats,err := stats.NewListStats(st.Data, list.List, task)
+		if err != nil {
+			return task.Error(err)
+		}
+
+		err = s.Write(&List{
+			Date: list.EventDate,
+			Scope: list.EventScope,
+			Country: list.EventCountry,
+			State: list.EventState,
+			Players: list.EventPlayers,
+			Rank: list.EventRank,
+			EliminationRank: list.EventElimination,
+			TopCut: list.TopCut(),
+			Percentile: percentile(list),
+			Player: list.PlayerName,
+			PlayerID: list.PlayerID,
+			Faction: list.List.Faction,
+			Archetype: archetypes.Name(list),
+			ShipPoints: liststats.SumShipPoints,
+			UpgradePoints: liststats.SumUpgradePoints,
+			TotalPoints: liststats.SumTotalPoints,
+			Ships: liststats.NumShips,
+			Uniques: liststats.NumUniques,
+			Large: liststats.NumLarge,
+			Small: liststats.NumSmall,
+			Skill: liststats.SumSkill,
+			Attack: liststats.SumAttack,
+			Agility: liststats.SumAgility,
+			Hull: liststats.SumHull,
+			Shields: liststats.SumShields,
+			ListID: list.ID(),
+			Pilots: listpilots(st.Data, list.List),
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+type RejectedList struct {
+	Tournament string
+	Date string
+	Scope string
+	Faction string
+	Reason string
+
+	ShipPoints int
+	UpgradePoints int
+	TotalPoints int
+
+	Pilots []ListPilot
+}
+
+func resolved(list *listjuggler.List) bool {
+	for _,pilotinstance := range(list.Pilots) {
+		if pilotinstance.Pilot == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func WriteRejectedLists(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write rejected lists", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,rejected := range(st.RejectedLists) {
+
+		r := &RejectedList{
+			Tournament: stats.TournamentID(rejected.File),
+			Date: rejected.EventDate,
+			Scope: rejected.EventScope,
+			Faction: rejected.List.Faction,
+			Reason: rejected.Reason,
+			Pilots: listpilots(st.Data, rejected.List),
+		}
+
+		if resolved(rejected.List) {
+			r.ShipPoints, r.UpgradePoints = rejected.List.Points(st.Data.Exceptions)
+			r.TotalPoints = r.ShipPoints + r.UpgradePoints
+		}
+
+		err = s.Write(r)
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rejected": s.Rows})
+
+}
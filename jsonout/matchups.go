@@ -0,0 +1,38 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+type Matchup struct {
+	*stats.Matchup
+	WinRate float64
+}
+
+func WriteMatchups(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write matchups", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,m := range(st.Matchups()) {
+		err = s.Write(&Matchup{m, m.WinRate()})
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows, "Matches": len(st.Matches)})
+
+}
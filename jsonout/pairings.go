@@ -0,0 +1,133 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+type Pairing struct {
+	Kind string
+	Pilot string
+	PilotXWS string
+	Faction string
+	Ship string
+	Rank int `json:",omitempty"`
+	Partner string
+	PartnerXWS string
+	Lists int
+	PilotLists int
+	PartnerLists int
+	Share float64
+	Lift float64
+	RankedLists int
+	AveragePercentile float64
+}
+
+func pairing(data *xwingdata.Data, p *stats.Pairing) (*Pairing,error) {
+
+	faction,err := xwingdata.FactionMap(p.Pilot.Faction)
+	if err != nil {
+		return nil,err
+	}
+
+	r := &Pairing{
+		Kind: p.Kind,
+		Pilot: data.PilotLabel(p.Pilot),
+		PilotXWS: p.Pilot.XWS,
+		Faction: faction,
+		Ship: p.Pilot.Ship,
+		Lists: p.Lists,
+		PilotLists: p.PilotLists,
+		PartnerLists: p.PartnerLists,
+		Share: p.Share(),
+		Lift: p.Lift(),
+		RankedLists: p.Ranked,
+		AveragePercentile: p.AveragePercentile(),
+	}
+
+	if p.Kind == stats.PairingPilot {
+		r.Partner = data.PilotLabel(p.Partner)
+		r.PartnerXWS = p.Partner.XWS
+	} else {
+		r.Partner = p.Upgrade.Name
+		r.PartnerXWS = p.Upgrade.XWS
+	}
+
+	return r,nil
+
+}
+
+func WritePairings(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write pairings", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,p := range(st.Pairings().Pairs) {
+		r,err := pairing(st.Data, p)
+		if err != nil {
+			return task.Error(err)
+		}
+		err = s.Write(r)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+func WriteTopPairings(file string, ndjson bool, st *stats.Stats, n int, parent *logberry.Task) error {
+
+	task := parent.Task("Write top pairings", logberry.D{"File": file, "Top": n})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	pairings := st.Pairings()
+
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+
+		for _,kind := range([]string{stats.PairingPilot, stats.PairingUpgrade}) {
+			for rank,p := range(pairings.Partners(pilot, kind, n)) {
+				r,err := pairing(st.Data, p)
+				if err != nil {
+					return task.Error(err)
+				}
+				r.Rank = rank+1
+				err = s.Write(r)
+				if err != nil {
+					return task.Error(err)
+				}
+			}
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
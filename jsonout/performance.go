@@ -0,0 +1,125 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+type Performance struct {
+	Lists int
+	RankedLists int
+	AveragePercentile float64
+	TopCutRate float64
+	PerformanceIndex float64
+}
+
+func performance(p *stats.Performance, expected *stats.Performance) Performance {
+	return Performance{
+		Lists: p.Lists,
+		RankedLists: p.Ranked,
+		AveragePercentile: p.AveragePercentile(),
+		TopCutRate: p.TopCutRate(),
+		PerformanceIndex: p.Index(expected),
+	}
+}
+
+type PilotPerformance struct {
+	Name string
+	XWS string
+	Faction string
+	Ship string
+	AllTime Performance
+	Recent Performance
+}
+
+func WritePilotPerformance(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write pilot performance", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			return task.Error(err)
+		}
+
+		p := st.PilotPerformance(pilot)
+		err = s.Write(&PilotPerformance{
+			Name: pilot.Name,
+			XWS: pilot.XWS,
+			Faction: faction,
+			Ship: pilot.Ship,
+			AllTime: performance(&p.AllTime, &st.Performance.AllTime),
+			Recent: performance(&p.Recent, &st.Performance.Recent),
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+type ShipPerformance struct {
+	Name string
+	XWS string
+	AllTime Performance
+	Recent Performance
+}
+
+func WriteShipPerformance(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write ship performance", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,ship := range(st.Data.Ships) {
+
+		if xwingdata.Contains(st.Data.Exceptions.ExcludedSizes, ship.Size) {
+			continue
+		}
+
+		p := st.ShipPerformance(ship)
+		err = s.Write(&ShipPerformance{
+			Name: ship.Name,
+			XWS: ship.XWS,
+			AllTime: performance(&p.AllTime, &st.Performance.AllTime),
+			Recent: performance(&p.Recent, &st.Performance.Recent),
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
@@ -0,0 +1,296 @@
+package jsonout
+
+import (
+	"sort"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+type Ship struct {
+	Name string
+	XWS string
+	Factions []string
+	Size string
+	Attack int
+	Agility int
+	Hull int
+	Shields int
+	Actions []string
+
+	AvailableSince string `json:",omitempty"`
+	Product string `json:",omitempty"`
+	Eligibility *stats.Eligibility `json:",omitempty"`
+}
+
+func WriteShips(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write ship stats", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,ship := range(st.Data.Ships) {
+
+		factions,err := ship.Factions()
+		if err != nil {
+			return task.Error(err, ship)
+		}
+
+		var eligibility *stats.Eligibility
+		if st.Data.HasReleases() {
+			eligibility = st.ShipEligibility(ship)
+		}
+
+		err = s.Write(&Ship{
+			Name: ship.Name,
+			XWS: ship.XWS,
+			Factions: array(factions),
+			Size: ship.Size,
+			Attack: ship.Attack,
+			Agility: ship.Agility,
+			Hull: ship.Hull,
+			Shields: ship.Shields,
+			Actions: array(ship.Actions),
+			AvailableSince: ship.Release.Date,
+			Product: ship.Release.Product,
+			Eligibility: eligibility,
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+type DuplicatePilot struct {
+	XWS string
+	Ship string
+	ShipXWS string
+}
+
+// Duplicate is a pilot name shared by several pilots.
+type Duplicate struct {
+	Name string
+	Pilots []DuplicatePilot
+}
+
+func WriteDuplicatePilots(file string, ndjson bool, data *xwingdata.Data, parent *logberry.Task) error {
+
+	task := parent.Task("Write duplicate pilots", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	var names []string
+	for k,l := range(data.PilotNames) {
+		if len(l) > 1 {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	for _,k := range(names) {
+		d := &Duplicate{Name: k}
+		for _,p := range(data.PilotNames[k]) {
+			d.Pilots = append(d.Pilots, DuplicatePilot{p.XWS, p.Chassis.Name, p.Chassis.XWS})
+		}
+
+		err = s.Write(d)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+// windows keys the uses in each extra window by its name.
+func windows(st *stats.Stats, c *stats.Counts) map[string]stats.Uses {
+	if len(st.Options.Windows) == 0 {
+		return nil
+	}
+	m := make(map[string]stats.Uses)
+	for i,w := range(st.Options.Windows) {
+		m[w.Name] = c.Window(i)
+	}
+	return m
+}
+
+type Pilot struct {
+	Name string
+	XWS string
+	Faction string
+	Ship string
+	Unique bool
+	Size string
+	Points int
+	Skill int
+	Attack int
+	Agility int
+	Hull int
+	Shields int
+	Slots []string
+	AvailableSince string `json:",omitempty"`
+	Product string `json:",omitempty"`
+
+	AllTime stats.Uses
+	Recent stats.Uses
+	Eligibility *stats.Eligibility `json:",omitempty"`
+	Windows map[string]stats.Uses `json:",omitempty"`
+}
+
+func WritePilots(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write pilot stats", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,pilot := range(st.Data.Pilots) {
+
+		if st.Data.Exceptions.ExcludedPilot(pilot) {
+			continue
+		}
+
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			return task.Error(err)
+		}
+
+		var eligibility *stats.Eligibility
+		if st.Data.HasReleases() {
+			eligibility = st.PilotEligibility(pilot)
+		}
+
+		counts := st.Pilot(pilot)
+		err = s.Write(&Pilot{
+			Name: pilot.Name,
+			XWS: pilot.XWS,
+			Faction: faction,
+			Ship: pilot.Ship,
+			Unique: pilot.Unique,
+			Size: pilot.Chassis.Size,
+			Points: int(pilot.Points),
+			Skill: int(pilot.Skill),
+			Attack: pilot.Chassis.Attack,
+			Agility: pilot.Chassis.Agility,
+			Hull: pilot.Chassis.Hull,
+			Shields: pilot.Chassis.Shields,
+			Slots: array(pilot.Slots),
+			AvailableSince: pilot.Release.Date,
+			Product: pilot.Release.Product,
+			AllTime: counts.AllTime,
+			Recent: counts.Recent,
+			Eligibility: eligibility,
+			Windows: windows(st, counts),
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+type Upgrade struct {
+	Name string
+	XWS string
+	Slot string
+	Points int
+	Unique bool
+	Limited bool
+	Faction string `json:",omitempty"`
+
+	AllTime stats.Uses
+	Recent stats.Uses
+	Windows map[string]stats.Uses `json:",omitempty"`
+}
+
+func WriteUpgrades(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write upgrade stats", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,upgrade := range(st.Data.Upgrades) {
+
+		if st.Data.Exceptions.ExcludedUpgrade(upgrade) {
+			continue
+		}
+
+		// The unreachable side of a dual-sided card
+		if upgrade.Code == "" {
+			continue
+		}
+
+		faction := ""
+		if upgrade.Faction != "" {
+			faction,err = xwingdata.FactionMap(upgrade.Faction)
+			if err != nil {
+				return task.Error(err)
+			}
+		}
+
+		counts := st.Upgrade(upgrade)
+		err = s.Write(&Upgrade{
+			Name: upgrade.Name,
+			XWS: upgrade.XWS,
+			Slot: upgrade.Slot,
+			Points: int(upgrade.Points),
+			Unique: upgrade.Unique,
+			Limited: upgrade.Limited,
+			Faction: faction,
+			AllTime: counts.AllTime,
+			Recent: counts.Recent,
+			Windows: windows(st, counts),
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
@@ -0,0 +1,83 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func WriteDataQuality(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write data quality report", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,summary := range(st.SummarizeIssues()) {
+		err = s.Write(summary)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Issues": s.Rows})
+
+}
+
+type SuggestedAlias struct {
+	Exception string
+	Unknown string
+	From string
+	To string
+	Count int
+	Applied bool
+	Candidates []xwingdata.Candidate
+}
+
+func WriteSuggestedAliases(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write suggested aliases", logberry.D{"File": file})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,suggestion := range(st.SortedSuggestions()) {
+		candidates := suggestion.Candidates
+		if candidates == nil {
+			candidates = []xwingdata.Candidate{}
+		}
+
+		err = s.Write(&SuggestedAlias{
+			Exception: suggestion.Exception,
+			Unknown: suggestion.Code,
+			From: suggestion.From,
+			To: suggestion.Best(),
+			Count: suggestion.Count,
+			Applied: suggestion.Applied,
+			Candidates: candidates,
+		})
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Suggestions": s.Rows})
+
+}
@@ -0,0 +1,41 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+func writeratings(file string, ndjson bool, ratings []*stats.Rated, task *logberry.Task) error {
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,r := range(ratings) {
+		err = s.Write(r)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
+
+func WriteRatings(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+	task := parent.Task("Write ratings", logberry.D{"File": file})
+	return writeratings(file, ndjson, st.Ratings().Current, task)
+}
+
+func WriteRatingHistory(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+	task := parent.Task("Write rating history", logberry.D{"File": file})
+	return writeratings(file, ndjson, st.Ratings().History, task)
+}
@@ -0,0 +1,99 @@
+// Package jsonout writes the compiled X-Wing Data and ListJuggler
+// statistics as JSON, with typed fields and structured lists.
+package jsonout
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// Stream writes records to a file, either as one indented JSON array
+// or as newline delimited JSON with one compact record per line.
+type Stream struct {
+	NDJSON bool
+	Rows int
+
+	f *os.File
+	w *bufio.Writer
+}
+
+func CreateStream(file string, ndjson bool) (*Stream,error) {
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil,err
+	}
+
+	return &Stream{
+		NDJSON: ndjson,
+		f: f,
+		w: bufio.NewWriter(f),
+	},nil
+
+}
+
+func (s *Stream) Write(record interface{}) error {
+
+	var bits []byte
+	var err error
+	if s.NDJSON {
+		bits, err = json.Marshal(record)
+	} else {
+		bits, err = json.MarshalIndent(record, "  ", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.NDJSON:
+	case s.Rows == 0:
+		s.w.WriteString("[\n  ")
+	default:
+		s.w.WriteString(",\n  ")
+	}
+
+	s.w.Write(bits)
+	if s.NDJSON {
+		s.w.WriteString("\n")
+	}
+
+	s.Rows++
+	return nil
+
+}
+
+// Close ends the array if there is one and closes the file, returning
+// any error from writing it.
+func (s *Stream) Close() error {
+
+	if s.f == nil {
+		return nil
+	}
+
+	if !s.NDJSON {
+		if s.Rows == 0 {
+			s.w.WriteString("[]\n")
+		} else {
+			s.w.WriteString("\n]\n")
+		}
+	}
+
+	err := s.w.Flush()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	s.f = nil
+
+	return err
+
+}
+
+// array keeps empty lists as arrays rather than nulls.
+func array(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,38 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+type SeriesPoint struct {
+	*stats.SeriesPoint
+	Share float64
+}
+
+func WriteTimeSeries(file string, ndjson bool, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write time series", logberry.D{"File": file, "Period": st.Options.Period})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for _,p := range(st.TimeSeries()) {
+		err = s.Write(&SeriesPoint{p, p.Share()})
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
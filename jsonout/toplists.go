@@ -0,0 +1,78 @@
+package jsonout
+
+import (
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+type ExactList struct {
+	Rank int `json:",omitempty"`
+	ID string
+	Difference string `json:",omitempty"`
+	Faction string
+	Lists int
+	Players int
+	RankedLists int
+	AveragePercentile float64
+	TopCutRate float64
+	FirstDate string
+	LastDate string
+	List string
+	Variants []*ExactList `json:",omitempty"`
+}
+
+func exactlist(e *stats.ExactList) *ExactList {
+	return &ExactList{
+		ID: e.ID,
+		Faction: e.Faction,
+		Lists: e.Lists,
+		Players: e.Players,
+		RankedLists: e.Ranked,
+		AveragePercentile: e.AveragePercentile(),
+		TopCutRate: e.TopCutRate(),
+		FirstDate: e.FirstDate,
+		LastDate: e.LastDate,
+		List: e.Text,
+	}
+}
+
+func WriteTopLists(file string, ndjson bool, st *stats.Stats, n int, parent *logberry.Task) error {
+
+	task := parent.Task("Write top lists", logberry.D{"File": file, "Top": n})
+
+	s, err := CreateStream(file, ndjson)
+	if err != nil {
+		return task.Error(err)
+	}
+	defer s.Close()
+
+	for rank,e := range(st.ExactLists()) {
+
+		if rank >= n || e.Lists < 2 {
+			break
+		}
+
+		r := exactlist(e)
+		r.Rank = rank+1
+		for _,v := range(e.Variants) {
+			x := exactlist(v.ExactList)
+			x.Difference = v.Difference
+			r.Variants = append(r.Variants, x)
+		}
+
+		err = s.Write(r)
+		if err != nil {
+			return task.Error(err)
+		}
+
+	}
+
+	err = s.Close()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Rows": s.Rows})
+
+}
@@ -0,0 +1,254 @@
+// Package listjuggler models the tournament reports published by
+// ListJuggler, whose lists follow the X-Wing Squadron Specification.
+package listjuggler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+const ListJugglerAPI = "http://lists.starwarsclubhouse.com/api/v1/"
+const TournamentListURL = ListJugglerAPI + "tournaments"
+const TournamentsFolder = "tournaments/"
+
+func TournamentURL(id int) string {
+	return fmt.Sprintf("%vtournament/%v", ListJugglerAPI, id)
+}
+
+type Venue struct {
+	Name string `json:"venue"`
+	Country string
+	City string
+	State string
+}
+
+type Upgrades struct {
+	Elite []string `json:"ept"`
+	Astromech []string `json:"amd"`
+	SalvagedAstromech []string `json:"samd"`
+	Crew []string
+	System []string
+	Tech []string
+	Turret []string
+	Torpedo []string
+	Missile []string
+	Cannon []string
+	Bomb []string
+	Illicit []string
+	Cargo []string
+	Hardpoint []string
+	Team []string
+	Title []string
+	Modification []string `json:"mod"`
+}
+
+type UpgradeSlot struct {
+	Slot string
+	XWS []string
+}
+
+// Slots returns the equipped upgrades grouped by their X-Wing Data
+// slot name, in a fixed order.
+func (u *Upgrades) Slots() []UpgradeSlot {
+	return []UpgradeSlot{
+		{"Elite", u.Elite},
+		{"Astromech", u.Astromech},
+		{"Salvaged Astromech", u.SalvagedAstromech},
+		{"Crew", u.Crew},
+		{"System", u.System},
+		{"Tech", u.Tech},
+		{"Turret", u.Turret},
+		{"Torpedo", u.Torpedo},
+		{"Missile", u.Missile},
+		{"Cannon", u.Cannon},
+		{"Bomb", u.Bomb},
+		{"Illicit", u.Illicit},
+		{"Cargo", u.Cargo},
+		{"Hardpoint", u.Hardpoint},
+		{"Team", u.Team},
+		{"Title", u.Title},
+		{"Modification", u.Modification},
+	}
+}
+
+type PilotInstance struct {
+	XWS string `json:"name"`
+	Ship string
+	Upgrades Upgrades
+
+	// Resolved against X-Wing Data.  The codes are kept alongside the
+	// cards so a resolved list can be cached and linked back up later.
+	Pilot *xwingdata.Pilot `json:"-"`
+	UpgradeCards []*xwingdata.Upgrade `json:"-"`
+	PilotCode string `json:",omitempty"`
+	UpgradeCodes []string `json:",omitempty"`
+}
+
+// Link sets the pilot and upgrade cards from previously resolved
+// codes, returning false if any are no longer in the data.
+func (p *PilotInstance) Link(data *xwingdata.Data) bool {
+
+	pilot,ok := data.PilotsXWS[p.PilotCode]
+	if !ok {
+		return false
+	}
+	p.Pilot = pilot
+
+	p.UpgradeCards = nil
+	for _,code := range(p.UpgradeCodes) {
+		upgrade,ok := data.UpgradesXWS[code]
+		if !ok {
+			return false
+		}
+		p.UpgradeCards = append(p.UpgradeCards, upgrade)
+	}
+
+	return true
+
+}
+
+func (p *PilotInstance) UpgradePoints(x *xwingdata.Exceptions) int {
+	return x.UpgradePoints(p.UpgradeCards)
+}
+
+type List struct {
+	Faction string
+	Pilots []*PilotInstance
+}
+
+// Points returns the squad's cost split into pilots and upgrades.
+func (l *List) Points(x *xwingdata.Exceptions) (int,int) {
+	ships := 0
+	upgrades := 0
+	for _,pilotinstance := range(l.Pilots) {
+		ships += int(pilotinstance.Pilot.Points)
+		upgrades += pilotinstance.UpgradePoints(x)
+	}
+	return ships,upgrades
+}
+
+type Rank struct {
+	Swiss int
+	Elimination int
+}
+
+type Player struct {
+	Name string
+	ID int
+	List *List
+	Rank Rank
+}
+
+// Match results as reported by ListJuggler
+const (
+	MatchWin = "win"
+	MatchDraw = "draw"
+	MatchBye = "bye"
+)
+
+type Match struct {
+	Player1 string
+	Player1Points int `json:"player1points"`
+	Player2 string
+	Player2Points int `json:"player2points"`
+	Result string
+}
+
+// Winner is 1 or 2 for the player that won the match, by points and
+// otherwise the first player as ListJuggler reports the winner first,
+// or 0 for a draw or a bye.
+func (m *Match) Winner() int {
+	switch {
+	case m.Result != MatchWin:
+		return 0
+	case m.Player2Points > m.Player1Points:
+		return 2
+	default:
+		return 1
+	}
+}
+
+type Round struct {
+	Number int `json:"round-number"`
+	Type string `json:"round-type"`
+	Matches []Match
+}
+
+type Tournament struct {
+	Name string
+	Date string
+	Scope string `json:"type"`
+	Format string
+	PlayerCount int `json:"participant_count"`
+	Venue Venue
+	RoundDuration int `json:"round_length"`
+	Players []Player
+	Rounds []Round
+}
+
+func errorline(js string, err error) int {
+
+	var offset int64
+
+	switch t := err.(type) {
+	case *json.SyntaxError:
+		offset = t.Offset
+	case *json.UnmarshalTypeError:
+		offset = t.Offset
+	default:
+		return -1
+	}
+	
+	line := strings.Count(js[:offset], "\n")
+
+	return line+1
+	
+}
+
+// ReadTournament loads a previously downloaded tournament report.
+func ReadTournament(file string, parent *logberry.Task) (*Tournament,error) {
+
+	task := parent.Task("Read tournament file", logberry.D{"File": file})
+
+	bits, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	tournament, err := ParseTournament(bits, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	return tournament,task.Success()
+
+}
+
+// ParseTournament unmarshals a tournament report.
+func ParseTournament(bits []byte, parent *logberry.Task) (*Tournament,error) {
+
+	task := parent.Task("Parse tournament")
+
+	// Unmarshal the tournament report
+	var fetch = struct{
+		Tournament *Tournament // Temporary structure to match file format
+	}{}
+	
+	err := json.Unmarshal(bits, &fetch)
+	if err != nil {
+		return nil,task.Error(err, logberry.D{"Error": fmt.Sprintf("%T", err), "Line": errorline(string(bits), err)})
+	}
+
+	if fetch.Tournament == nil {
+		return nil,task.Failure("No tournament in report")
+	}
+
+	return fetch.Tournament,task.Success()
+
+}
@@ -0,0 +1,134 @@
+package sqlout
+
+// schema creates the database tables.  Cards are numbered in the order
+// they appear in X-Wing Data, tournaments keep their ListJuggler IDs,
+// and players are identified by name across tournaments.
+var schema = []string{
+
+	`CREATE TABLE ships (
+		id INTEGER PRIMARY KEY,
+		xws TEXT NOT NULL,
+		name TEXT NOT NULL,
+		factions TEXT NOT NULL,
+		size TEXT NOT NULL,
+		attack INTEGER NOT NULL,
+		agility INTEGER NOT NULL,
+		hull INTEGER NOT NULL,
+		shields INTEGER NOT NULL,
+		actions TEXT NOT NULL,
+		available_since TEXT
+	)`,
+
+	`CREATE TABLE pilots (
+		id INTEGER PRIMARY KEY,
+		code TEXT NOT NULL UNIQUE,
+		xws TEXT NOT NULL,
+		name TEXT NOT NULL,
+		label TEXT NOT NULL,
+		faction TEXT NOT NULL,
+		ship_id INTEGER NOT NULL REFERENCES ships(id),
+		is_unique INTEGER NOT NULL,
+		points INTEGER NOT NULL,
+		skill INTEGER NOT NULL,
+		slots TEXT NOT NULL,
+		available_since TEXT
+	)`,
+	`CREATE INDEX pilots_ship ON pilots(ship_id)`,
+
+	`CREATE TABLE upgrades (
+		id INTEGER PRIMARY KEY,
+		code TEXT NOT NULL UNIQUE,
+		xws TEXT NOT NULL,
+		name TEXT NOT NULL,
+		slot TEXT NOT NULL,
+		points INTEGER NOT NULL,
+		is_unique INTEGER NOT NULL,
+		is_limited INTEGER NOT NULL,
+		faction TEXT
+	)`,
+
+	`CREATE TABLE tournaments (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		date TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		format TEXT NOT NULL,
+		venue TEXT,
+		city TEXT,
+		state TEXT,
+		country TEXT,
+		players INTEGER NOT NULL
+	)`,
+	`CREATE INDEX tournaments_date ON tournaments(date)`,
+
+	`CREATE TABLE players (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE
+	)`,
+
+	`CREATE TABLE lists (
+		id INTEGER PRIMARY KEY,
+		tournament_id TEXT NOT NULL REFERENCES tournaments(id),
+		player_id INTEGER REFERENCES players(id),
+		listjuggler_player_id INTEGER,
+		rank INTEGER NOT NULL,
+		elimination_rank INTEGER,
+		percentile REAL,
+		faction TEXT NOT NULL,
+		archetype TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		ship_points INTEGER NOT NULL,
+		upgrade_points INTEGER NOT NULL,
+		total_points INTEGER NOT NULL,
+		ships INTEGER NOT NULL,
+		uniques INTEGER NOT NULL,
+		large INTEGER NOT NULL,
+		small INTEGER NOT NULL,
+		skill INTEGER NOT NULL,
+		attack INTEGER NOT NULL,
+		agility INTEGER NOT NULL,
+		hull INTEGER NOT NULL,
+		shields INTEGER NOT NULL
+	)`,
+	`CREATE INDEX lists_tournament ON lists(tournament_id)`,
+	`CREATE INDEX lists_player ON lists(player_id)`,
+	`CREATE INDEX lists_faction ON lists(faction)`,
+	`CREATE INDEX lists_archetype ON lists(archetype)`,
+	`CREATE INDEX lists_fingerprint ON lists(fingerprint)`,
+
+	`CREATE TABLE list_pilots (
+		id INTEGER PRIMARY KEY,
+		list_id INTEGER NOT NULL REFERENCES lists(id),
+		position INTEGER NOT NULL,
+		pilot_id INTEGER NOT NULL REFERENCES pilots(id),
+		upgrade_points INTEGER NOT NULL
+	)`,
+	`CREATE INDEX list_pilots_list ON list_pilots(list_id)`,
+	`CREATE INDEX list_pilots_pilot ON list_pilots(pilot_id)`,
+
+	`CREATE TABLE list_pilot_upgrades (
+		list_pilot_id INTEGER NOT NULL REFERENCES list_pilots(id),
+		upgrade_id INTEGER NOT NULL REFERENCES upgrades(id)
+	)`,
+	`CREATE INDEX list_pilot_upgrades_list_pilot ON list_pilot_upgrades(list_pilot_id)`,
+	`CREATE INDEX list_pilot_upgrades_upgrade ON list_pilot_upgrades(upgrade_id)`,
+
+	`CREATE TABLE matches (
+		id INTEGER PRIMARY KEY,
+		tournament_id TEXT NOT NULL REFERENCES tournaments(id),
+		round INTEGER NOT NULL,
+		round_type TEXT NOT NULL,
+		player1 TEXT NOT NULL,
+		player2 TEXT,
+		player1_points INTEGER NOT NULL,
+		player2_points INTEGER NOT NULL,
+		list1_id INTEGER REFERENCES lists(id),
+		list2_id INTEGER REFERENCES lists(id),
+		result TEXT NOT NULL,
+		winner INTEGER
+	)`,
+	`CREATE INDEX matches_tournament ON matches(tournament_id)`,
+	`CREATE INDEX matches_list1 ON matches(list1_id)`,
+	`CREATE INDEX matches_list2 ON matches(list2_id)`,
+
+}
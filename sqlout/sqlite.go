@@ -0,0 +1,326 @@
+// Package sqlout writes the compiled X-Wing Data and ListJuggler
+// statistics as a normalized SQLite database.
+package sqlout
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/RocketshipGames/xwing-csv/stats"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// nullable stores empty strings and zero IDs as NULL.
+func nullable(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		if x == "" {
+			return nil
+		}
+	case int:
+		if x == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// percentile stores a list without a placement as NULL.
+func percentile(l *stats.ListInstance) interface{} {
+	if p,ok := l.Percentile(); ok {
+		return p
+	}
+	return nil
+}
+
+// WriteSQLite replaces file with a database of the cards and every
+// tabulated tournament, player, list, and match.
+func WriteSQLite(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write SQLite database", logberry.D{"File": file})
+
+	err := os.Remove(file)
+	if err != nil && !os.IsNotExist(err) {
+		return task.Error(err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:" + file + "?_foreign_keys=1")
+	if err != nil {
+		return task.Error(err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return task.Error(err)
+	}
+	defer tx.Rollback()
+
+	for _,statement := range(schema) {
+		_, err = tx.Exec(statement)
+		if err != nil {
+			return task.WrapError("Could not create schema", err, logberry.D{"Statement": statement})
+		}
+	}
+
+	w := &writer{
+		task: task,
+		tx: tx,
+		data: st.Data,
+		ships: make(map[*xwingdata.Ship]int),
+		pilots: make(map[*xwingdata.Pilot]int),
+		upgrades: make(map[*xwingdata.Upgrade]int),
+		players: make(map[string]int),
+		lists: make(map[*stats.ListInstance]int),
+	}
+
+	for _,step := range([]func(*stats.Stats) error{
+		w.writecards,
+		w.writetournaments,
+		w.writelists,
+		w.writematches,
+	}) {
+		err = step(st)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{
+		"Tournaments": st.AllTime.Tournaments,
+		"Players": len(w.players),
+		"Lists": len(st.Lists),
+		"Matches": len(st.Matches),
+	})
+
+}
+
+// writer tracks the rows inserted so far to link later ones to them.
+type writer struct {
+	task *logberry.Task
+	tx *sql.Tx
+	data *xwingdata.Data
+
+	ships map[*xwingdata.Ship]int
+	pilots map[*xwingdata.Pilot]int
+	upgrades map[*xwingdata.Upgrade]int
+	players map[string]int
+	lists map[*stats.ListInstance]int
+}
+
+func (w *writer) writecards(st *stats.Stats) error {
+
+	ship, err := w.tx.Prepare(`INSERT INTO ships VALUES (?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer ship.Close()
+
+	for i,s := range(w.data.Ships) {
+		factions,err := s.Factions()
+		if err != nil {
+			return err
+		}
+
+		id := i+1
+		_, err = ship.Exec(id, s.XWS, s.Name, strings.Join(factions, ","), s.Size,
+			s.Attack, s.Agility, s.Hull, s.Shields, strings.Join(s.Actions, ","), nullable(s.Release.Date))
+		if err != nil {
+			return err
+		}
+		w.ships[s] = id
+	}
+
+	pilot, err := w.tx.Prepare(`INSERT INTO pilots VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer pilot.Close()
+
+	for i,p := range(w.data.Pilots) {
+		faction,err := xwingdata.FactionMap(p.Faction)
+		if err != nil {
+			return err
+		}
+
+		id := i+1
+		_, err = pilot.Exec(id, p.Code, p.XWS, p.Name, w.data.PilotLabel(p), faction,
+			w.ships[p.Chassis], p.Unique, int(p.Points), int(p.Skill), strings.Join(p.Slots, ","), nullable(p.Release.Date))
+		if err != nil {
+			return err
+		}
+		w.pilots[p] = id
+	}
+
+	upgrade, err := w.tx.Prepare(`INSERT INTO upgrades VALUES (?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer upgrade.Close()
+
+	for i,u := range(w.data.Upgrades) {
+
+		// The unreachable side of a dual-sided card
+		if u.Code == "" {
+			continue
+		}
+
+		faction := ""
+		if u.Faction != "" {
+			faction,err = xwingdata.FactionMap(u.Faction)
+			if err != nil {
+				return err
+			}
+		}
+
+		id := i+1
+		_, err = upgrade.Exec(id, u.Code, u.XWS, u.Name, u.Slot, int(u.Points),
+			u.Unique, u.Limited, nullable(faction))
+		if err != nil {
+			return err
+		}
+		w.upgrades[u] = id
+	}
+
+	return nil
+
+}
+
+func (w *writer) writetournaments(st *stats.Stats) error {
+
+	tournament, err := w.tx.Prepare(`INSERT INTO tournaments VALUES (?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer tournament.Close()
+
+	for _,t := range(st.Tournaments) {
+		if !t.Included() {
+			continue
+		}
+
+		_, err = tournament.Exec(t.ID, t.Name, t.Date, t.Scope, t.Format,
+			nullable(t.Venue), nullable(t.City), nullable(t.State), nullable(t.Country), t.Players)
+		if err != nil {
+			return err
+		}
+	}
+
+	player, err := w.tx.Prepare(`INSERT INTO players (id, name) VALUES (?,?)`)
+	if err != nil {
+		return err
+	}
+	defer player.Close()
+
+	// Players are matched by name ignoring case, as in the player stats
+	for _,list := range(st.Lists) {
+		key := stats.PlayerKey(list.PlayerName)
+		if _,ok := w.players[key]; ok || key == "" {
+			continue
+		}
+
+		id := len(w.players)+1
+		_, err = player.Exec(id, strings.TrimSpace(list.PlayerName))
+		if err != nil {
+			return err
+		}
+		w.players[key] = id
+	}
+
+	return nil
+
+}
+
+func (w *writer) writelists(st *stats.Stats) error {
+
+	list, err := w.tx.Prepare(`INSERT INTO lists VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer list.Close()
+
+	pilot, err := w.tx.Prepare(`INSERT INTO list_pilots VALUES (?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer pilot.Close()
+
+	upgrade, err := w.tx.Prepare(`INSERT INTO list_pilot_upgrades VALUES (?,?)`)
+	if err != nil {
+		return err
+	}
+	defer upgrade.Close()
+
+	archetypes := st.Archetypes()
+
+	listpilots := 0
+	for i,l := range(st.Lists) {
+
+		x,err := stats.NewListStats(w.data, l.List, w.task)
+		if err != nil {
+			return err
+		}
+
+		id := i+1
+		w.lists[l] = id
+		_, err = list.Exec(id, l.EventID, nullable(w.players[stats.PlayerKey(l.PlayerName)]),
+			nullable(l.PlayerID), l.EventRank, nullable(l.EventElimination), percentile(l), l.List.Faction, archetypes.Name(l), l.ID(),
+			x.SumShipPoints, x.SumUpgradePoints, x.SumTotalPoints,
+			x.NumShips, x.NumUniques, x.NumLarge, x.NumSmall,
+			x.SumSkill, x.SumAttack, x.SumAgility, x.SumHull, x.SumShields)
+		if err != nil {
+			return err
+		}
+
+		for position,pilotinstance := range(l.List.Pilots) {
+			listpilots++
+			_, err = pilot.Exec(listpilots, id, position+1, w.pilots[pilotinstance.Pilot],
+				pilotinstance.UpgradePoints(w.data.Exceptions))
+			if err != nil {
+				return err
+			}
+
+			for _,card := range(pilotinstance.UpgradeCards) {
+				_, err = upgrade.Exec(listpilots, w.upgrades[card])
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+	}
+
+	return nil
+
+}
+
+func (w *writer) writematches(st *stats.Stats) error {
+
+	match, err := w.tx.Prepare(`INSERT INTO matches VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer match.Close()
+
+	for i,m := range(st.Matches) {
+		_, err = match.Exec(i+1, m.EventID, m.Round, m.RoundType,
+			m.Players[0], nullable(m.Players[1]), m.Points[0], m.Points[1],
+			nullable(w.lists[m.List(1)]), nullable(w.lists[m.List(2)]),
+			m.Result, nullable(m.Winner))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
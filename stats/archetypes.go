@@ -0,0 +1,368 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Similarity measures between lists, comparing their canonical pilot
+// and key upgrade codes as multisets
+var Similarities = map[string]func(a, b map[string]int) float64{
+	"jaccard": jaccard,
+	"cosine": cosine,
+}
+
+const DefaultSimilarity = "jaccard"
+const DefaultArchetypeThreshold = 0.5
+
+// jaccard is the size of the intersection over the size of the union.
+func jaccard(a, b map[string]int) float64 {
+	intersection, union := 0, 0
+	for code,n := range(a) {
+		m := b[code]
+		if n < m {
+			intersection += n
+			union += m
+		} else {
+			intersection += m
+			union += n
+		}
+	}
+	for code,m := range(b) {
+		if _,ok := a[code]; !ok {
+			union += m
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection)/float64(union)
+}
+
+func cosine(a, b map[string]int) float64 {
+	dot, na, nb := 0.0, 0.0, 0.0
+	for code,n := range(a) {
+		dot += float64(n*b[code])
+		na += float64(n*n)
+	}
+	for _,m := range(b) {
+		nb += float64(m*m)
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot/math.Sqrt(na*nb)
+}
+
+// Canonical is a list reduced to what identifies its archetype: its
+// faction, its pilots, and the key upgrades it carries, each sorted.
+// Pilots are identified by their codes, as in CanonicalList, so pilots
+// sharing an XWS code on different ships stay distinct.
+type Canonical struct {
+	Faction string
+	Codes map[string]int
+	Text string
+}
+
+// Canonicalize reduces a list to its canonical form, counting the
+// given upgrades alongside the pilots.
+func Canonicalize(list *ListInstance, upgrades []string) *Canonical {
+
+	c := &Canonical{
+		Faction: list.List.Faction,
+		Codes: make(map[string]int),
+	}
+
+	var pilots, keys []string
+	for _,pilotinstance := range(list.List.Pilots) {
+		code := pilotinstance.Pilot.Code
+		c.Codes[code]++
+
+		// The faction is already given once for the whole list
+		pilots = append(pilots, strings.TrimPrefix(code, c.Faction + "/"))
+
+		for _,upgrade := range(pilotinstance.UpgradeCards) {
+			if xwingdata.Contains(upgrades, upgrade.XWS) {
+				keys = append(keys, upgrade.XWS)
+				c.Codes["+" + upgrade.XWS]++
+			}
+		}
+	}
+	sort.Strings(pilots)
+	sort.Strings(keys)
+
+	c.Text = c.Faction + ": " + strings.Join(pilots, " ")
+	if len(keys) > 0 {
+		c.Text += " + " + strings.Join(keys, " ")
+	}
+
+	return c
+
+}
+
+// ArchetypePeriod is how an archetype fared over one period.
+type ArchetypePeriod struct {
+	Period string
+	Performance
+
+	// All the tabulated lists in the period
+	TotalLists int
+}
+
+// Share is the percentage of the period's lists in the archetype.
+func (p *ArchetypePeriod) Share() float64 {
+	if p.TotalLists == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(p.Lists)/float64(p.TotalLists))/10
+}
+
+// Archetype is a cluster of similar lists.
+type Archetype struct {
+	Name string
+	Faction string
+
+	// The most common canonical list in the cluster, which the others
+	// were compared against, and how many distinct ones there are
+	Leader string
+	Variants int
+
+	Lists []*ListInstance
+
+	AllTime ArchetypePeriod
+	Recent ArchetypePeriod
+
+	// By period, in order
+	Periods []*ArchetypePeriod
+}
+
+type Archetypes struct {
+	// Every archetype, most lists first
+	List []*Archetype
+
+	lists map[*ListInstance]*Archetype
+}
+
+// Of returns a list's archetype, nil if it wasn't clustered.
+func (a *Archetypes) Of(list *ListInstance) *Archetype {
+	return a.lists[list]
+}
+
+// Name returns the name of a list's archetype, empty if it has none.
+func (a *Archetypes) Name(list *ListInstance) string {
+	if archetype := a.lists[list]; archetype != nil {
+		return archetype.Name
+	}
+	return ""
+}
+
+type variant struct {
+	canonical *Canonical
+	lists []*ListInstance
+}
+
+// Archetypes clusters the tabulated lists, computing them the first
+// time.  Distinct canonical lists are taken most common first, each
+// joining the archetype of its faction whose leader it is most similar
+// to, if at least ArchetypeThreshold, and otherwise leading a new one.
+func (s *Stats) Archetypes() *Archetypes {
+
+	if s.archetypes != nil {
+		return s.archetypes
+	}
+
+	similarity := Similarities[s.Options.ArchetypeSimilarity]
+	if similarity == nil {
+		similarity = Similarities[DefaultSimilarity]
+	}
+
+	variants := make(map[string]*variant)
+	var ordered []*variant
+	for _,l := range(s.Lists) {
+		c := Canonicalize(l, s.Options.ArchetypeUpgrades)
+		v,ok := variants[c.Text]
+		if !ok {
+			v = &variant{canonical: c}
+			variants[c.Text] = v
+			ordered = append(ordered, v)
+		}
+		v.lists = append(v.lists, l)
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if len(ordered[i].lists) != len(ordered[j].lists) {
+			return len(ordered[i].lists) > len(ordered[j].lists)
+		}
+		return ordered[i].canonical.Text < ordered[j].canonical.Text
+	})
+
+	type cluster struct {
+		leader *Canonical
+		variants []*variant
+	}
+	var clusters []*cluster
+
+	for _,v := range(ordered) {
+		var best *cluster
+		score := 0.0
+		for _,c := range(clusters) {
+			if c.leader.Faction != v.canonical.Faction {
+				continue
+			}
+			if x := similarity(c.leader.Codes, v.canonical.Codes); x > score {
+				best = c
+				score = x
+			}
+		}
+		if best == nil || score < s.Options.ArchetypeThreshold {
+			best = &cluster{leader: v.canonical}
+			clusters = append(clusters, best)
+		}
+		best.variants = append(best.variants, v)
+	}
+
+	archetypes := &Archetypes{
+		lists: make(map[*ListInstance]*Archetype),
+	}
+
+	for _,c := range(clusters) {
+		a := &Archetype{
+			Faction: c.leader.Faction,
+			Leader: c.leader.Text,
+			Variants: len(c.variants),
+		}
+		for _,v := range(c.variants) {
+			a.Lists = append(a.Lists, v.lists...)
+		}
+		a.Name = s.archetypename(a)
+		archetypes.List = append(archetypes.List, a)
+	}
+
+	sort.SliceStable(archetypes.List, func(i, j int) bool {
+		return len(archetypes.List[i].Lists) > len(archetypes.List[j].Lists)
+	})
+
+	// Archetypes that come out with the same name are numbered, most
+	// lists first
+	names := make(map[string]int)
+	for _,a := range(archetypes.List) {
+		names[a.Name]++
+		if n := names[a.Name]; n > 1 {
+			a.Name = fmt.Sprintf("%v (%v)", a.Name, n)
+		}
+	}
+
+	s.archetypeperiods(archetypes)
+
+	for _,a := range(archetypes.List) {
+		for _,l := range(a.Lists) {
+			archetypes.lists[l] = a
+		}
+	}
+
+	s.archetypes = archetypes
+	return archetypes
+
+}
+
+// archetypename names an archetype by the pilots flown in at least
+// half its lists, most common first and at most three, or else its
+// single most common pilot.  Pilots in as many lists are ordered by
+// how many copies are flown.
+func (s *Stats) archetypename(a *Archetype) string {
+
+	counts := make(map[*xwingdata.Pilot]int)
+	copies := make(map[*xwingdata.Pilot]int)
+	var pilots []*xwingdata.Pilot
+	for _,l := range(a.Lists) {
+		seen := make(map[*xwingdata.Pilot]bool)
+		for _,pilotinstance := range(l.List.Pilots) {
+			pilot := pilotinstance.Pilot
+			copies[pilot]++
+			if seen[pilot] {
+				continue
+			}
+			seen[pilot] = true
+			if counts[pilot] == 0 {
+				pilots = append(pilots, pilot)
+			}
+			counts[pilot]++
+		}
+	}
+
+	sort.SliceStable(pilots, func(i, j int) bool {
+		if counts[pilots[i]] != counts[pilots[j]] {
+			return counts[pilots[i]] > counts[pilots[j]]
+		}
+		if copies[pilots[i]] != copies[pilots[j]] {
+			return copies[pilots[i]] > copies[pilots[j]]
+		}
+		return s.Data.PilotLabel(pilots[i]) < s.Data.PilotLabel(pilots[j])
+	})
+
+	var names []string
+	for _,pilot := range(pilots) {
+		if len(names) > 0 && (len(names) >= 3 || 2*counts[pilot] < len(a.Lists)) {
+			break
+		}
+		names = append(names, s.Data.PilotLabel(pilot))
+	}
+
+	return strings.Join(names, " + ")
+
+}
+
+// archetypeperiods tallies each archetype's performance for all time,
+// recently, and by period.
+func (s *Stats) archetypeperiods(archetypes *Archetypes) {
+
+	periods := make(map[string]int)
+	recent := 0
+	for _,l := range(s.Lists) {
+		if date,err := time.Parse("2006-01-02", l.EventDate); err == nil {
+			periods[s.Options.PeriodOf(date)]++
+			if s.IsRecent(date) {
+				recent++
+			}
+		}
+	}
+
+	for _,a := range(archetypes.List) {
+
+		a.AllTime = ArchetypePeriod{Period: "All Time", TotalLists: len(s.Lists)}
+		a.Recent = ArchetypePeriod{Period: "Recent", TotalLists: recent}
+
+		byperiod := make(map[string]*ArchetypePeriod)
+		for _,l := range(a.Lists) {
+			a.AllTime.Add(l)
+
+			date,err := time.Parse("2006-01-02", l.EventDate)
+			if err != nil {
+				continue
+			}
+			if s.IsRecent(date) {
+				a.Recent.Add(l)
+			}
+
+			period := s.Options.PeriodOf(date)
+			p,ok := byperiod[period]
+			if !ok {
+				p = &ArchetypePeriod{Period: period, TotalLists: periods[period]}
+				byperiod[period] = p
+				a.Periods = append(a.Periods, p)
+			}
+			p.Add(l)
+		}
+
+		sort.Slice(a.Periods, func(i, j int) bool {
+			return a.Periods[i].Period < a.Periods[j].Period
+		})
+
+	}
+
+}
@@ -0,0 +1,173 @@
+package stats
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// testpilots builds pilots from faction/ship/pilot codes, named by
+// their pilot code.
+type testpilots map[string]*xwingdata.Pilot
+
+func (p testpilots) list(faction string, codes ...string) *ListInstance {
+
+	list := &listjuggler.List{Faction: faction}
+	for _,code := range(codes) {
+		pilot,ok := p[code]
+		if !ok {
+			parts := strings.Split(code, "/")
+			pilot = &xwingdata.Pilot{
+				Name: parts[2],
+				XWS: parts[2],
+				Faction: parts[0],
+				Code: code,
+				Chassis: &xwingdata.Ship{Name: parts[1], XWS: parts[1]},
+			}
+			p[code] = pilot
+		}
+		list.Pilots = append(list.Pilots, &listjuggler.PilotInstance{XWS: pilot.XWS, Ship: pilot.Chassis.XWS, Pilot: pilot})
+	}
+
+	return &ListInstance{EventDate: "2016-12-10", List: list}
+
+}
+
+func TestSimilarities(t *testing.T) {
+
+	a := map[string]int{"x": 2, "y": 1}
+	b := map[string]int{"x": 1, "y": 1, "z": 1}
+
+	cases := []struct {
+		name string
+		a, b map[string]int
+		expected float64
+	}{
+		{"jaccard", a, b, 0.5},
+		{"jaccard", a, a, 1},
+		{"jaccard", a, map[string]int{}, 0},
+		{"cosine", a, b, 3/math.Sqrt(15)},
+		{"cosine", a, a, 1},
+		{"cosine", a, map[string]int{}, 0},
+	}
+
+	for _,c := range(cases) {
+		if x := Similarities[c.name](c.a, c.b); !near(x, c.expected, 1e-9) {
+			t.Errorf("%v of %v and %v is %v, expected %v", c.name, c.a, c.b, x, c.expected)
+		}
+	}
+
+}
+
+func TestCanonicalize(t *testing.T) {
+
+	pilots := make(testpilots)
+	list := pilots.list("rebel", "rebel/xwing/wedgeantilles", "rebel/xwing/lukeskywalker")
+	list.List.Pilots[1].UpgradeCards = []*xwingdata.Upgrade{{XWS: "r2d2"}, {XWS: "shieldupgrade"}}
+
+	cases := []struct {
+		upgrades []string
+		text string
+	}{
+		{nil, "rebel: xwing/lukeskywalker xwing/wedgeantilles"},
+		{[]string{"r2d2"}, "rebel: xwing/lukeskywalker xwing/wedgeantilles + r2d2"},
+	}
+
+	for _,c := range(cases) {
+		if x := Canonicalize(list, c.upgrades); x.Text != c.text {
+			t.Errorf("Canonicalized with %v as %q, expected %q", c.upgrades, x.Text, c.text)
+		}
+	}
+
+}
+
+func TestArchetypes(t *testing.T) {
+
+	type archetype struct {
+		name string
+		leader string
+		variants int
+		lists int
+	}
+
+	pilots := make(testpilots)
+	wedge := "rebel/xwing/wedgeantilles"
+	biggs := "rebel/xwing/biggsdarklighter"
+	luke := "rebel/xwing/lukeskywalker"
+	rookie := "rebel/xwing/rookiepilot"
+	han := "rebel/yt1300/hansolo"
+
+	lists := []*ListInstance{
+		pilots.list("rebel", wedge, biggs, luke),
+		pilots.list("rebel", biggs, luke, wedge),
+		pilots.list("rebel", wedge, biggs, rookie),
+		pilots.list("rebel", luke, wedge, biggs),
+		pilots.list("rebel", han, rookie),
+	}
+
+	cases := []struct {
+		name string
+		similarity string
+		threshold float64
+		expected []archetype
+	}{
+		{
+			"Similar lists join the leader's archetype",
+			"jaccard", 0.5,
+			[]archetype{
+				{"biggsdarklighter + wedgeantilles + lukeskywalker", "rebel: xwing/biggsdarklighter xwing/lukeskywalker xwing/wedgeantilles", 2, 4},
+				{"hansolo + rookiepilot", "rebel: xwing/rookiepilot yt1300/hansolo", 1, 1},
+			},
+		},
+		{
+			"A higher threshold splits them",
+			"jaccard", 0.6,
+			[]archetype{
+				{"biggsdarklighter + lukeskywalker + wedgeantilles", "rebel: xwing/biggsdarklighter xwing/lukeskywalker xwing/wedgeantilles", 1, 3},
+				{"biggsdarklighter + rookiepilot + wedgeantilles", "rebel: xwing/biggsdarklighter xwing/rookiepilot xwing/wedgeantilles", 1, 1},
+				{"hansolo + rookiepilot", "rebel: xwing/rookiepilot yt1300/hansolo", 1, 1},
+			},
+		},
+		{
+			"Cosine similarity",
+			"cosine", 0.6,
+			[]archetype{
+				{"biggsdarklighter + wedgeantilles + lukeskywalker", "rebel: xwing/biggsdarklighter xwing/lukeskywalker xwing/wedgeantilles", 2, 4},
+				{"hansolo + rookiepilot", "rebel: xwing/rookiepilot yt1300/hansolo", 1, 1},
+			},
+		},
+	}
+
+	for _,c := range(cases) {
+
+		options := DefaultOptions()
+		options.ArchetypeSimilarity = c.similarity
+		options.ArchetypeThreshold = c.threshold
+
+		st := New(&xwingdata.Data{}, options)
+		st.Lists = lists
+
+		archetypes := st.Archetypes()
+		if len(archetypes.List) != len(c.expected) {
+			t.Errorf("%v: %v archetypes, expected %v", c.name, len(archetypes.List), len(c.expected))
+			continue
+		}
+		for i,expected := range(c.expected) {
+			a := archetypes.List[i]
+			x := archetype{a.Name, a.Leader, a.Variants, len(a.Lists)}
+			if x != expected {
+				t.Errorf("%v: archetype %v is %+v, expected %+v", c.name, i, x, expected)
+			}
+			for _,l := range(a.Lists) {
+				if archetypes.Of(l) != a {
+					t.Errorf("%v: list %v isn't mapped to archetype %v", c.name, Canonicalize(l, nil).Text, a.Name)
+				}
+			}
+		}
+
+	}
+
+}
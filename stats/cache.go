@@ -0,0 +1,190 @@
+package stats
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+//
+// The cache keeps the processed result of each tournament report,
+// keyed by file name and checked against the report's content hash,
+// so that later compiles only process new or changed reports.  Results
+// depend on X-Wing Data, the exceptions, the included formats and
+// scopes, and how unknown codes are aliased, so the whole cache is
+// discarded if its fingerprint of those doesn't match.
+//
+
+const CacheFile = "tournaments.cache"
+const CacheVersion = 10
+
+type Cache struct {
+	Version int
+	Fingerprint string
+	Tournaments map[string]*TournamentResult
+
+	file string
+	changed bool
+}
+
+// Fingerprint hashes everything that processing a tournament depends
+// on besides the report itself.
+func Fingerprint(data *xwingdata.Data, options Options) (string,error) {
+
+	bits, err := json.Marshal(struct{
+		Version int
+		Exceptions *xwingdata.Exceptions
+		Ships []*xwingdata.Ship
+		Pilots []*xwingdata.Pilot
+		Upgrades []*xwingdata.Upgrade
+		Formats []string
+		Scopes []string
+		Lenient bool
+		AutoAlias float64
+	}{
+		CacheVersion,
+		data.Exceptions,
+		data.Ships,
+		data.Pilots,
+		data.Upgrades,
+		options.Formats,
+		options.Scopes,
+		options.Lenient,
+		options.AutoAlias,
+	})
+	if err != nil {
+		return "",err
+	}
+
+	return fetch.ContentHash(bits),nil
+
+}
+
+// LoadCache reads the cache from file, starting an empty one if it
+// doesn't exist or was built from different data.
+func LoadCache(file string, fingerprint string, parent *logberry.Task) (*Cache,error) {
+
+	task := parent.Task("Load cache", logberry.D{"File": file})
+
+	c := &Cache{
+		Version: CacheVersion,
+		Fingerprint: fingerprint,
+		Tournaments: make(map[string]*TournamentResult),
+		file: file,
+	}
+
+	bits, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return c,task.Success(logberry.D{"Status": "New"})
+	}
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	var previous Cache
+	err = json.Unmarshal(bits, &previous)
+	if err != nil {
+		task.Warning("Discarding unreadable cache", err)
+		return c,task.Success(logberry.D{"Status": "Discarded"})
+	}
+
+	if previous.Version != CacheVersion || previous.Fingerprint != fingerprint {
+		return c,task.Success(logberry.D{"Status": "Stale"})
+	}
+
+	c.Tournaments = previous.Tournaments
+	return c,task.Success(logberry.D{"Status": "Loaded", "Tournaments": len(c.Tournaments)})
+
+}
+
+func linklist(list *listjuggler.List, data *xwingdata.Data) bool {
+	for _,pilotinstance := range(list.Pilots) {
+		if !pilotinstance.Link(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the cached result for file if its content hash matches,
+// with its lists linked back up to data.  A nil cache has nothing.
+func (c *Cache) Get(file string, hash string, data *xwingdata.Data) *TournamentResult {
+
+	if c == nil {
+		return nil
+	}
+
+	result,ok := c.Tournaments[file]
+	if !ok || result.SHA256 != hash {
+		return nil
+	}
+
+	for _,listinstance := range(result.Lists) {
+		if !linklist(listinstance.List, data) {
+			return nil
+		}
+	}
+
+	// Lists rejected in lenient mode for an unknown pilot or ship have
+	// pilots that were never resolved, which are left unlinked
+	for _,rejected := range(result.RejectedLists) {
+		for _,pilotinstance := range(rejected.List.Pilots) {
+			if pilotinstance.PilotCode != "" && !pilotinstance.Link(data) {
+				return nil
+			}
+		}
+	}
+
+	return result
+
+}
+
+func (c *Cache) Put(file string, result *TournamentResult) {
+	if c == nil {
+		return
+	}
+	c.Tournaments[file] = result
+	c.changed = true
+}
+
+// Prune drops results for files no longer present.
+func (c *Cache) Prune(files map[string]bool) {
+	if c == nil {
+		return
+	}
+	for file := range(c.Tournaments) {
+		if !files[file] {
+			delete(c.Tournaments, file)
+			c.changed = true
+		}
+	}
+}
+
+// Save writes the cache back out if it has changed.
+func (c *Cache) Save(parent *logberry.Task) error {
+
+	if c == nil || !c.changed {
+		return nil
+	}
+
+	task := parent.Task("Save cache", logberry.D{"File": c.file})
+
+	bits, err := json.Marshal(c)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	err = ioutil.WriteFile(c.file, bits, 0644)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Tournaments": len(c.Tournaments)})
+
+}
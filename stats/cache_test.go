@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func cachedata() *xwingdata.Data {
+	wedge := &xwingdata.Pilot{Name: "Wedge Antilles", XWS: "wedgeantilles", Code: "rebel/xwing/wedgeantilles"}
+	return &xwingdata.Data{
+		Exceptions: &xwingdata.Exceptions{},
+		PilotsXWS: map[string]*xwingdata.Pilot{wedge.Code: wedge},
+		Pilots: []*xwingdata.Pilot{wedge},
+		UpgradesXWS: map[string]*xwingdata.Upgrade{},
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+
+	base,err := Fingerprint(cachedata(), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		change func(*xwingdata.Data, *Options)
+		same bool
+	}{
+		{"Unchanged", func(d *xwingdata.Data, o *Options) {}, true},
+		{"Recent window", func(d *xwingdata.Data, o *Options) { o.Recent.Months = 6 }, true},
+		{"Exceptions", func(d *xwingdata.Data, o *Options) { d.Exceptions.FactionLabels = []string{"Boba Fett"} }, false},
+		{"Pilot points", func(d *xwingdata.Data, o *Options) { d.Pilots[0].Points = 29 }, false},
+		{"Scopes", func(d *xwingdata.Data, o *Options) { o.Scopes = []string{"Regional"} }, false},
+		{"Lenient", func(d *xwingdata.Data, o *Options) { o.Lenient = true }, false},
+		{"Auto alias", func(d *xwingdata.Data, o *Options) { o.AutoAlias = 0.9 }, false},
+	}
+
+	for _,c := range(cases) {
+		data := cachedata()
+		options := DefaultOptions()
+		c.change(data, &options)
+
+		fingerprint,err := Fingerprint(data, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same := fingerprint == base; same != c.same {
+			t.Errorf("%v: fingerprint unchanged %v, expected %v", c.name, same, c.same)
+		}
+	}
+
+}
+
+func TestCache(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), CacheFile)
+
+	cache,err := LoadCache(file, "fingerprint", logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put("101.json", &TournamentResult{
+		SHA256: "hash",
+		File: "101.json",
+		Lists: []*ListInstance{{List: &listjuggler.List{Faction: "rebel", Pilots: []*listjuggler.PilotInstance{{PilotCode: "rebel/xwing/wedgeantilles"}}}}},
+	})
+	cache.Put("102.json", &TournamentResult{SHA256: "hash", File: "102.json"})
+	cache.Prune(map[string]bool{"101.json": true})
+	if err = cache.Save(logberry.Main); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		fingerprint string
+		file string
+		hash string
+		data *xwingdata.Data
+		hit bool
+	}{
+		{"Unchanged", "fingerprint", "101.json", "hash", cachedata(), true},
+		{"Report changed", "fingerprint", "101.json", "newhash", cachedata(), false},
+		{"Report pruned", "fingerprint", "102.json", "hash", cachedata(), false},
+		{"Report not cached", "fingerprint", "103.json", "hash", cachedata(), false},
+		{"Fingerprint changed", "newfingerprint", "101.json", "hash", cachedata(), false},
+		{"Pilot no longer known", "fingerprint", "101.json", "hash", &xwingdata.Data{}, false},
+	}
+
+	for _,c := range(cases) {
+		cache,err := LoadCache(file, c.fingerprint, logberry.Main)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := cache.Get(c.file, c.hash, c.data)
+		if (result != nil) != c.hit {
+			t.Errorf("%v: cache hit %v, expected %v", c.name, result != nil, c.hit)
+		}
+		if result != nil && result.Lists[0].List.Pilots[0].Pilot != c.data.PilotsXWS["rebel/xwing/wedgeantilles"] {
+			t.Errorf("%v: cached list isn't linked to the pilot", c.name)
+		}
+	}
+
+	if err = ioutil.WriteFile(file, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache,err = LoadCache(file, "fingerprint", logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Get("101.json", "hash", cachedata()) != nil {
+		t.Errorf("Unreadable cache hit")
+	}
+
+	var none *Cache
+	if none.Get("101.json", "hash", cachedata()) != nil {
+		t.Errorf("Disabled cache hit")
+	}
+
+}
+
+func TestCacheLenient(t *testing.T) {
+
+	report := []byte(`{"tournament": {
+		"date": "2016-12-10",
+		"type": "Store Championship",
+		"format": "Standard - 100 Point Dogfight",
+		"players": [
+			{"name": "Alice", "list": {"faction": "rebel", "pilots": [{"name": "wedgeantilles", "ship": "xwing"}]}},
+			{"name": "Bob", "list": {"faction": "rebel", "pilots": [{"name": "wedgeantilles", "ship": "xwing"}, {"name": "ltlorrir", "ship": "tieinterceptor"}]}}
+		]
+	}}`)
+
+	options := DefaultOptions()
+	options.Lenient = true
+
+	result,err := New(cachedata(), options).ProcessTournament("105.json", report, logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Lists) != 1 || len(result.RejectedLists) != 1 {
+		t.Fatalf("Processed %v lists and %v rejected, expected 1 and 1", len(result.Lists), len(result.RejectedLists))
+	}
+	result.SHA256 = "hash"
+
+	file := filepath.Join(t.TempDir(), CacheFile)
+
+	cache,err := LoadCache(file, "fingerprint", logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Put("105.json", result)
+	if err = cache.Save(logberry.Main); err != nil {
+		t.Fatal(err)
+	}
+
+	cache,err = LoadCache(file, "fingerprint", logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := cachedata()
+	cached := cache.Get("105.json", "hash", data)
+	if cached == nil {
+		t.Fatalf("Tournament with a rejected list missed the cache")
+	}
+
+	pilots := cached.RejectedLists[0].List.Pilots
+	if pilots[0].Pilot != data.PilotsXWS["rebel/xwing/wedgeantilles"] {
+		t.Errorf("Resolved pilot in the rejected list isn't linked")
+	}
+	if pilots[1].Pilot != nil {
+		t.Errorf("Unknown pilot in the rejected list is linked to %v", pilots[1].Pilot)
+	}
+
+}
@@ -0,0 +1,248 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// canonicalpilot is a pilot in a list with its upgrades sorted, keyed
+// by their resolved codes.
+type canonicalpilot struct {
+	Key string
+	Pilot *xwingdata.Pilot
+	Upgrades []*xwingdata.Upgrade
+}
+
+func canonicalpilots(list *listjuggler.List) []*canonicalpilot {
+
+	var pilots []*canonicalpilot
+	for _,pilotinstance := range(list.Pilots) {
+		p := &canonicalpilot{
+			Pilot: pilotinstance.Pilot,
+			Upgrades: append([]*xwingdata.Upgrade{}, pilotinstance.UpgradeCards...),
+		}
+		sort.Slice(p.Upgrades, func(i, j int) bool {
+			return p.Upgrades[i].Code < p.Upgrades[j].Code
+		})
+
+		codes := make([]string, len(p.Upgrades))
+		for i,upgrade := range(p.Upgrades) {
+			codes[i] = upgrade.Code
+		}
+		p.Key = p.Pilot.Code + "[" + strings.Join(codes, ",") + "]"
+
+		pilots = append(pilots, p)
+	}
+
+	sort.Slice(pilots, func(i, j int) bool {
+		return pilots[i].Key < pilots[j].Key
+	})
+
+	return pilots
+
+}
+
+// CanonicalList describes a list by its faction and its pilots and
+// their upgrades' resolved XWS codes, each sorted, so the same squad
+// is described the same way however it was entered.
+func CanonicalList(list *listjuggler.List) string {
+	var keys []string
+	for _,p := range(canonicalpilots(list)) {
+		keys = append(keys, p.Key)
+	}
+	return list.Faction + ":" + strings.Join(keys, ";")
+}
+
+// ListID is a stable hash identifying a squad by its canonical form.
+func ListID(list *listjuggler.List) string {
+	return fetch.ContentHash([]byte(CanonicalList(list)))[:16]
+}
+
+func (l *ListInstance) ID() string {
+	return ListID(l.List)
+}
+
+// ListText names a list's pilots and their upgrades in canonical
+// order, such as "Han Solo (Chewbacca), Wedge Antilles (R2-D2)".
+func ListText(data *xwingdata.Data, list *listjuggler.List) string {
+	var pilots []string
+	for _,p := range(canonicalpilots(list)) {
+		text := data.PilotLabel(p.Pilot)
+		if len(p.Upgrades) > 0 {
+			var upgrades []string
+			for _,upgrade := range(p.Upgrades) {
+				upgrades = append(upgrades, upgrade.Name)
+			}
+			text += " (" + strings.Join(upgrades, ", ") + ")"
+		}
+		pilots = append(pilots, text)
+	}
+	return strings.Join(pilots, ", ")
+}
+
+// ExactList is every tabulated list flying the same squad.
+type ExactList struct {
+	ID string
+	Faction string
+	Text string
+	Performance
+
+	Players int
+	FirstDate string
+	LastDate string
+
+	// Squads that differ from this one by a single upgrade, most
+	// played first
+	Variants []*ListVariant
+
+	pilots []*canonicalpilot
+}
+
+// ListVariant is a squad differing from another by adding, removing,
+// or swapping one upgrade, as described by Difference.
+type ListVariant struct {
+	*ExactList
+	Difference string
+}
+
+// ExactLists groups the tabulated lists by squad, most played first,
+// and finds each squad's variants.
+func (s *Stats) ExactLists() []*ExactList {
+
+	exact := make(map[string]*ExactList)
+	players := make(map[string]map[string]bool)
+	var list []*ExactList
+
+	for _,l := range(s.Lists) {
+		id := l.ID()
+		e,ok := exact[id]
+		if !ok {
+			e = &ExactList{
+				ID: id,
+				Faction: l.List.Faction,
+				Text: ListText(s.Data, l.List),
+				pilots: canonicalpilots(l.List),
+			}
+			exact[id] = e
+			players[id] = make(map[string]bool)
+			list = append(list, e)
+		}
+
+		e.Add(l)
+		if key := PlayerKey(l.PlayerName); key != "" {
+			players[id][key] = true
+		}
+		if l.EventDate != "" && (e.FirstDate == "" || l.EventDate < e.FirstDate) {
+			e.FirstDate = l.EventDate
+		}
+		if l.EventDate > e.LastDate {
+			e.LastDate = l.EventDate
+		}
+	}
+
+	for id,p := range(players) {
+		exact[id].Players = len(p)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Lists != list[j].Lists {
+			return list[i].Lists > list[j].Lists
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	// Only squads of the same faction flying the same pilots can be
+	// variants of each other
+	skeletons := make(map[string][]*ExactList)
+	for _,e := range(list) {
+		key := skeletonkey(e)
+		skeletons[key] = append(skeletons[key], e)
+	}
+
+	for _,e := range(list) {
+		for _,other := range(skeletons[skeletonkey(e)]) {
+			if other == e {
+				continue
+			}
+			if difference,ok := s.variant(e, other); ok {
+				e.Variants = append(e.Variants, &ListVariant{other, difference})
+			}
+		}
+	}
+
+	return list
+
+}
+
+// skeletonkey identifies a squad by its faction and pilots alone.
+func skeletonkey(e *ExactList) string {
+	codes := make([]string, len(e.pilots))
+	for i,p := range(e.pilots) {
+		codes[i] = p.Pilot.Code
+	}
+	sort.Strings(codes)
+	return e.Faction + ":" + strings.Join(codes, ";")
+}
+
+// variant describes how other differs from e if it is by a single
+// upgrade on one pilot.
+func (s *Stats) variant(e *ExactList, other *ExactList) (string,bool) {
+
+	left := leftover(e.pilots, other.pilots)
+	right := leftover(other.pilots, e.pilots)
+	if len(left) != 1 || len(right) != 1 {
+		return "",false
+	}
+
+	removed := leftoverupgrades(left[0].Upgrades, right[0].Upgrades)
+	added := leftoverupgrades(right[0].Upgrades, left[0].Upgrades)
+	on := " on " + s.Data.PilotLabel(left[0].Pilot)
+
+	switch {
+	case len(removed) == 1 && len(added) == 0:
+		return "-" + removed[0].Name + on,true
+	case len(removed) == 0 && len(added) == 1:
+		return "+" + added[0].Name + on,true
+	case len(removed) == 1 && len(added) == 1:
+		return removed[0].Name + " to " + added[0].Name + on,true
+	}
+	return "",false
+
+}
+
+// leftover gives the pilots in a that aren't matched by one in b.
+func leftover(a []*canonicalpilot, b []*canonicalpilot) []*canonicalpilot {
+	counts := make(map[string]int)
+	for _,p := range(b) {
+		counts[p.Key]++
+	}
+	var left []*canonicalpilot
+	for _,p := range(a) {
+		if counts[p.Key] > 0 {
+			counts[p.Key]--
+			continue
+		}
+		left = append(left, p)
+	}
+	return left
+}
+
+func leftoverupgrades(a []*xwingdata.Upgrade, b []*xwingdata.Upgrade) []*xwingdata.Upgrade {
+	counts := make(map[*xwingdata.Upgrade]int)
+	for _,u := range(b) {
+		counts[u]++
+	}
+	var left []*xwingdata.Upgrade
+	for _,u := range(a) {
+		if counts[u] > 0 {
+			counts[u]--
+			continue
+		}
+		left = append(left, u)
+	}
+	return left
+}
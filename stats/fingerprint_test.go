@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+var (
+	r2d2 = &xwingdata.Upgrade{Name: "R2-D2", XWS: "r2d2", Code: "r2d2"}
+	r2f2 = &xwingdata.Upgrade{Name: "R2-F2", XWS: "r2f2", Code: "r2f2"}
+	shields = &xwingdata.Upgrade{Name: "Shield Upgrade", XWS: "shieldupgrade", Code: "shieldupgrade"}
+)
+
+// equip gives each pilot in a list its upgrades, in order.
+func equip(list *ListInstance, upgrades ...[]*xwingdata.Upgrade) *ListInstance {
+	for i,u := range(upgrades) {
+		list.List.Pilots[i].UpgradeCards = u
+	}
+	return list
+}
+
+func TestListID(t *testing.T) {
+
+	pilots := make(testpilots)
+	wedge := "rebel/xwing/wedgeantilles"
+	luke := "rebel/xwing/lukeskywalker"
+	base := equip(pilots.list("rebel", wedge, luke), nil, []*xwingdata.Upgrade{r2d2, shields})
+
+	cases := []struct {
+		name string
+		list *ListInstance
+		same bool
+	}{
+		{"Pilots reordered", equip(pilots.list("rebel", luke, wedge), []*xwingdata.Upgrade{r2d2, shields}, nil), true},
+		{"Upgrades reordered", equip(pilots.list("rebel", wedge, luke), nil, []*xwingdata.Upgrade{shields, r2d2}), true},
+		{"Upgrade moved", equip(pilots.list("rebel", wedge, luke), []*xwingdata.Upgrade{shields}, []*xwingdata.Upgrade{r2d2}), false},
+		{"Upgrade swapped", equip(pilots.list("rebel", wedge, luke), nil, []*xwingdata.Upgrade{r2f2, shields}), false},
+		{"Same XWS on another ship", equip(pilots.list("rebel", wedge, "rebel/t65xwing/lukeskywalker"), nil, []*xwingdata.Upgrade{r2d2, shields}), false},
+	}
+
+	if CanonicalList(base.List) != "rebel:rebel/xwing/lukeskywalker[r2d2,shieldupgrade];rebel/xwing/wedgeantilles[]" {
+		t.Errorf("Canonical list is %q", CanonicalList(base.List))
+	}
+
+	for _,c := range(cases) {
+		if same := c.list.ID() == base.ID(); same != c.same {
+			t.Errorf("%v: %v gives the same ID %v, expected %v", c.name, CanonicalList(c.list.List), same, c.same)
+		}
+	}
+
+}
+
+func TestExactLists(t *testing.T) {
+
+	pilots := make(testpilots)
+	wedge := "rebel/xwing/wedgeantilles"
+	luke := "rebel/xwing/lukeskywalker"
+	list := func(date string, player string, upgrades ...*xwingdata.Upgrade) *ListInstance {
+		l := equip(pilots.list("rebel", wedge, luke), nil, upgrades)
+		l.EventDate = date
+		l.PlayerName = player
+		return l
+	}
+
+	st := New(&xwingdata.Data{}, DefaultOptions())
+	st.Lists = []*ListInstance{
+		list("2016-03-01", "Alice", r2d2),
+		list("2016-01-01", "alice", r2d2),
+		list("2016-02-01", "Bob", r2d2),
+		list("2016-02-01", "Carol"),
+		list("2016-02-01", "Dave", r2d2, shields),
+		list("2016-02-01", "Erin", r2f2),
+		list("2016-02-01", "Frank", r2f2, shields),
+	}
+
+	type variant struct {
+		text string
+		difference string
+	}
+
+	cases := []struct {
+		text string
+		lists int
+		players int
+		first string
+		last string
+		variants []variant
+	}{
+		{"lukeskywalker (R2-D2), wedgeantilles", 3, 2, "2016-01-01", "2016-03-01", []variant{
+			{"lukeskywalker, wedgeantilles", "-R2-D2 on lukeskywalker"},
+			{"lukeskywalker (R2-D2, Shield Upgrade), wedgeantilles", "+Shield Upgrade on lukeskywalker"},
+			{"lukeskywalker (R2-F2), wedgeantilles", "R2-D2 to R2-F2 on lukeskywalker"},
+		}},
+	}
+
+	exact := st.ExactLists()
+	if len(exact) != 5 {
+		t.Fatalf("%v exact lists, expected 5", len(exact))
+	}
+
+	for i,c := range(cases) {
+		e := exact[i]
+		if e.Text != c.text || e.Lists != c.lists || e.Players != c.players || e.FirstDate != c.first || e.LastDate != c.last {
+			t.Errorf("Exact list %v is %q with %v lists by %v players from %v to %v, expected %q with %v by %v from %v to %v",
+				i, e.Text, e.Lists, e.Players, e.FirstDate, e.LastDate, c.text, c.lists, c.players, c.first, c.last)
+		}
+
+		variants := make(map[variant]bool)
+		for _,v := range(e.Variants) {
+			variants[variant{v.Text, v.Difference}] = true
+		}
+		if len(variants) != len(c.variants) {
+			t.Errorf("Exact list %v has variants %v, expected %v", i, variants, c.variants)
+		}
+		for _,v := range(c.variants) {
+			if !variants[v] {
+				t.Errorf("Exact list %v is missing variant %v", i, v)
+			}
+		}
+	}
+
+}
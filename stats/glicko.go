@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"math"
+)
+
+// Glicko-2 as described by Glickman in "Example of the Glicko-2
+// system", with ratings kept on the Glicko scale and converted for
+// each update.
+const (
+	GlickoRating = 1500.0
+	GlickoRD = 350.0
+	GlickoVolatility = 0.06
+
+	// Constrains how much the volatility can change per period
+	GlickoTau = 0.5
+
+	glickoScale = 173.7178
+	glickoEpsilon = 0.000001
+)
+
+type Glicko struct {
+	Rating float64
+	RD float64
+	Volatility float64
+}
+
+func NewGlicko() Glicko {
+	return Glicko{GlickoRating, GlickoRD, GlickoVolatility}
+}
+
+// glickoGame is one game against an opponent rated as of the start
+// of the period, scored 1 for a win, 0.5 for a draw, and 0 for a loss.
+type glickoGame struct {
+	Opponent Glicko
+	Score float64
+}
+
+// Rounded gives the rating and deviation to a tenth of a point for
+// output, and the volatility to six places.
+func (g Glicko) Rounded() Glicko {
+	return Glicko{
+		Rating: math.Round(10*g.Rating)/10,
+		RD: math.Round(10*g.RD)/10,
+		Volatility: math.Round(1e6*g.Volatility)/1e6,
+	}
+}
+
+func (g Glicko) mu() float64 {
+	return (g.Rating - GlickoRating)/glickoScale
+}
+
+func (g Glicko) phi() float64 {
+	return g.RD/glickoScale
+}
+
+func glickog(phi float64) float64 {
+	return 1/math.Sqrt(1 + 3*phi*phi/(math.Pi*math.Pi))
+}
+
+// Idle is the rating after a period without games, in which only the
+// deviation grows.
+func (g Glicko) Idle() Glicko {
+	phi := g.phi()
+	g.RD = math.Min(GlickoRD, glickoScale*math.Sqrt(phi*phi + g.Volatility*g.Volatility))
+	return g
+}
+
+// Update is the rating after a period with the given games.
+func (g Glicko) Update(games []glickoGame) Glicko {
+
+	if len(games) == 0 {
+		return g.Idle()
+	}
+
+	mu := g.mu()
+	phi := g.phi()
+
+	v := 0.0
+	delta := 0.0
+	for _,game := range(games) {
+		gphi := glickog(game.Opponent.phi())
+		e := 1/(1 + math.Exp(-gphi*(mu - game.Opponent.mu())))
+		v += gphi*gphi*e*(1 - e)
+		delta += gphi*(game.Score - e)
+	}
+	v = 1/v
+	delta *= v
+
+	sigma := g.volatility(phi, v, delta)
+
+	phistar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1/math.Sqrt(1/(phistar*phistar) + 1/v)
+	mu += phi*phi*delta/v
+
+	return Glicko{
+		Rating: glickoScale*mu + GlickoRating,
+		RD: math.Min(GlickoRD, glickoScale*phi),
+		Volatility: sigma,
+	}
+
+}
+
+// volatility finds the new volatility by the Illinois algorithm.
+func (g Glicko) volatility(phi float64, v float64, delta float64) float64 {
+
+	a := math.Log(g.Volatility*g.Volatility)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta - phi*phi - v - ex)/(2*d*d) - (x - a)/(GlickoTau*GlickoTau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi + v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a - k*GlickoTau) < 0 {
+			k++
+		}
+		B = a - k*GlickoTau
+	}
+
+	fA := f(A)
+	fB := f(B)
+	for math.Abs(B - A) > glickoEpsilon {
+		C := A + (A - B)*fA/(fB - fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A = B
+			fA = fB
+		} else {
+			fA /= 2
+		}
+		B = C
+		fB = fC
+	}
+
+	return math.Exp(A/2)
+
+}
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a float64, b float64, tolerance float64) bool {
+	return math.Abs(a - b) <= tolerance
+}
+
+func TestGlickoUpdate(t *testing.T) {
+
+	cases := []struct {
+		name string
+		player Glicko
+		games []glickoGame
+		expected Glicko
+	}{
+		// Glickman's worked example in "Example of the Glicko-2 system"
+		{
+			"Glickman example",
+			Glicko{1500, 200, 0.06},
+			[]glickoGame{
+				{Glicko{1400, 30, 0.06}, 1},
+				{Glicko{1550, 100, 0.06}, 0},
+				{Glicko{1700, 300, 0.06}, 0},
+			},
+			Glicko{1464.05, 151.52, 0.059996},
+		},
+		{
+			"Draw between equals",
+			Glicko{1500, 200, 0.06},
+			[]glickoGame{{Glicko{1500, 200, 0.06}, 0.5}},
+			Glicko{1500, 180.08, 0.059998},
+		},
+		{
+			"No games",
+			Glicko{1500, 200, 0.06},
+			nil,
+			Glicko{1500, 200.27, 0.06},
+		},
+		{
+			"Deviation capped",
+			Glicko{1500, 350, 0.06},
+			nil,
+			Glicko{1500, 350, 0.06},
+		},
+	}
+
+	for _,c := range(cases) {
+		g := c.player.Update(c.games)
+		if !near(g.Rating, c.expected.Rating, 0.01) || !near(g.RD, c.expected.RD, 0.01) || !near(g.Volatility, c.expected.Volatility, 0.000001) {
+			t.Errorf("%v: rated %.2f / %.2f / %.6f, expected %.2f / %.2f / %.6f", c.name,
+				g.Rating, g.RD, g.Volatility, c.expected.Rating, c.expected.RD, c.expected.Volatility)
+		}
+	}
+
+}
+
+func TestGlickoRounded(t *testing.T) {
+	g := Glicko{1464.0506, 151.5165, 0.0599959}.Rounded()
+	if g != (Glicko{1464.1, 151.5, 0.059996}) {
+		t.Errorf("Rounded to %v", g)
+	}
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"sort"
+)
+
+// Issue is a data quality problem found in a tournament report.
+type Issue struct {
+	Kind string
+	Detail string
+	Tournament string
+}
+
+// IssueSummary gathers every occurrence of the same issue.
+type IssueSummary struct {
+	Kind string
+	Detail string
+	Count int
+	Tournaments []string
+}
+
+// SummarizeIssues groups the issues by kind and detail, ordered by
+// kind and then most frequent.
+func (s *Stats) SummarizeIssues() []*IssueSummary {
+
+	type key struct {
+		kind string
+		detail string
+	}
+
+	summaries := make(map[key]*IssueSummary)
+	var list []*IssueSummary
+	for _,issue := range(s.Issues) {
+		k := key{issue.Kind, issue.Detail}
+		summary,ok := summaries[k]
+		if !ok {
+			summary = &IssueSummary{Kind: issue.Kind, Detail: issue.Detail}
+			summaries[k] = summary
+			list = append(list, summary)
+		}
+		summary.Count++
+		if n := len(summary.Tournaments); n == 0 || summary.Tournaments[n-1] != issue.Tournament {
+			summary.Tournaments = append(summary.Tournaments, issue.Tournament)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Count > list[j].Count
+	})
+
+	return list
+
+}
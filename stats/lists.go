@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+type ListInstance struct {
+
+	EventID string
+	EventCountry string
+	EventState string
+	EventScope string
+	EventDate string
+	EventPlayers int
+	EventRank int
+	EventElimination int
+
+	PlayerName string
+	PlayerID int
+
+	List *listjuggler.List
+
+}
+
+// TopCut reports whether the list made the elimination rounds.
+func (l *ListInstance) TopCut() bool {
+	return l.EventElimination > 0
+}
+
+// Placement is the list's final standing, by its elimination rank if
+// it made the cut and otherwise its swiss rank.
+func (l *ListInstance) Placement() int {
+	if l.TopCut() {
+		return l.EventElimination
+	}
+	return l.EventRank
+}
+
+// Percentile places the list from 100 for first to 0 for last.  Lists
+// reported without a rank have no placement, and so no percentile.
+func (l *ListInstance) Percentile() (float64,bool) {
+	if l.Placement() <= 0 {
+		return 0,false
+	}
+	if l.EventPlayers <= 1 {
+		return 100,true
+	}
+	p := 100*float64(l.EventPlayers - l.Placement())/float64(l.EventPlayers - 1)
+	return math.Max(0, math.Min(100, math.Round(10*p)/10)),true
+}
+
+// Whether a tournament's lists were tabulated
+const (
+	TournamentIncluded = "Included"
+	TournamentSkipped = "Skipped"
+	TournamentQuarantined = "Quarantined"
+)
+
+// TournamentInstance is a tournament report and what was made of it.
+type TournamentInstance struct {
+	ID string
+	Name string
+	Date string
+	Scope string
+	Format string
+	Venue string
+	City string
+	State string
+	Country string
+
+	// Players is the larger of the count declared by the organizer and
+	// the number of players actually reported
+	Players int
+	DeclaredPlayers int
+	ReportedPlayers int
+	RoundLength int
+
+	// Lists tabulated
+	Lists int
+
+	Status string
+	Reason string `json:",omitempty"`
+}
+
+// Coverage is the percentage of players whose lists were tabulated.
+func (t *TournamentInstance) Coverage() float64 {
+	if t.Players == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(t.Lists)/float64(t.Players))/10
+}
+
+func (t *TournamentInstance) Included() bool {
+	return t.Status == TournamentIncluded
+}
+
+type RejectedList struct {
+	File string
+	EventDate string
+	EventScope string
+	Reason string
+	List *listjuggler.List
+}
+
+type ListStats struct {
+	
+	SumShipPoints int
+	SumUpgradePoints int
+	SumTotalPoints int
+	
+	NumShips int
+	NumUniques int
+	NumLarge int
+	NumSmall int
+
+	SumSkill int
+	SumAttack int
+	SumAgility int
+	SumHull int
+	SumShields int
+
+	Text string
+
+}
+
+func NewListStats(data *xwingdata.Data, list *listjuggler.List, parent *logberry.Task) (*ListStats,error) {
+
+	task := parent.Task("Calculate list stats")
+
+	var x ListStats
+	
+	for _,pilotinstance := range(list.Pilots) {
+		pilot := pilotinstance.Pilot
+		
+		x.SumShipPoints += int(pilot.Points)
+		x.SumUpgradePoints += pilotinstance.UpgradePoints(data.Exceptions)
+		x.SumTotalPoints = x.SumShipPoints + x.SumUpgradePoints
+		x.SumSkill += int(pilot.Skill)
+		
+		x.SumAttack += pilot.Chassis.Attack
+		x.SumAgility += pilot.Chassis.Agility
+		x.SumHull += pilot.Chassis.Hull
+		x.SumShields += pilot.Chassis.Shields
+			
+		if pilot.Unique {
+			x.NumUniques++
+		}
+			
+		switch pilot.Chassis.Size {
+		case "large":
+			x.NumLarge++
+		case "small":
+			x.NumSmall++
+		default:
+			return nil,task.Failure("Unknown ship size", pilot.Chassis.Size)
+		}
+
+		x.NumShips++
+
+		descrip := data.PilotLabel(pilot)
+		
+		if x.Text != "" {
+			x.Text = x.Text + ", "
+		}
+		x.Text = x.Text + descrip
+
+	}
+
+	return &x,task.Success()
+
+}
@@ -0,0 +1,231 @@
+package stats
+
+import (
+	"math"
+	"sort"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// MatchInstance is one game reported in a tournament's rounds.
+type MatchInstance struct {
+	EventID string
+	EventDate string
+	Round int
+	RoundType string
+
+	Players [2]string
+	Points [2]int
+	Result string
+
+	// 1 or 2 for the player that won, 0 for a draw or a bye
+	Winner int
+
+	// Each player's list as an index into the tournament's tabulated
+	// lists, or -1 if it wasn't tabulated.  Indexes rather than
+	// pointers so the match can be cached alongside the lists.
+	Lists [2]int
+
+	// Whether each player is missing from the tournament's players, and
+	// so can't be identified
+	Unknown [2]bool
+
+	lists [2]*ListInstance
+}
+
+// Played reports whether the match was a game between two tabulated
+// lists, rather than a bye or a game against an unknown list.
+func (m *MatchInstance) Played() bool {
+	return m.Result != listjuggler.MatchBye && m.lists[0] != nil && m.lists[1] != nil
+}
+
+// List returns the tabulated list of player 1 or 2, nil if there is
+// none.
+func (m *MatchInstance) List(player int) *ListInstance {
+	return m.lists[player-1]
+}
+
+// addmatches records a tournament's rounds, linking each player to
+// their list by name.  Lists without a player name can't be linked.
+// Players that aren't in the tournament are noted.
+func (r *TournamentResult) addmatches(tournament *listjuggler.Tournament) {
+
+	players := make(map[string]bool)
+	for _,player := range(tournament.Players) {
+		players[PlayerKey(player.Name)] = true
+	}
+
+	lists := make(map[string]int)
+	for i,list := range(r.Lists) {
+		if key := PlayerKey(list.PlayerName); key != "" {
+			lists[key] = i
+		}
+	}
+
+	unknown := func(name string) bool {
+		return name != "" && !players[PlayerKey(name)]
+	}
+
+	index := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		if i,ok := lists[PlayerKey(name)]; ok {
+			return i
+		}
+		if unknown(name) {
+			r.issue("Unknown match player", name)
+		}
+		return -1
+	}
+
+	for _,round := range(tournament.Rounds) {
+		for _,match := range(round.Matches) {
+			r.Matches = append(r.Matches, &MatchInstance{
+				EventID: r.Tournament.ID,
+				EventDate: r.Date,
+				Round: round.Number,
+				RoundType: round.Type,
+				Players: [2]string{match.Player1, match.Player2},
+				Points: [2]int{match.Player1Points, match.Player2Points},
+				Result: match.Result,
+				Winner: match.Winner(),
+				Lists: [2]int{index(match.Player1), index(match.Player2)},
+				Unknown: [2]bool{unknown(match.Player1), unknown(match.Player2)},
+			})
+		}
+	}
+
+}
+
+// link points a cached match back at its tournament's lists.
+func (m *MatchInstance) link(lists []*ListInstance) {
+	for i,index := range(m.Lists) {
+		m.lists[i] = nil
+		if index >= 0 && index < len(lists) {
+			m.lists[i] = lists[index]
+		}
+	}
+}
+
+// Kinds of matchups
+const (
+	MatchupFaction = "Faction"
+	MatchupShip = "Ship"
+)
+
+// Matchup is how one faction or ship fared against another.
+type Matchup struct {
+	Kind string
+	Side string
+	Opponent string
+
+	Games int
+	Wins int
+	Losses int
+	Draws int
+}
+
+// WinRate is the percentage of games won, counting draws as half.
+func (m *Matchup) WinRate() float64 {
+	if m.Games == 0 {
+		return 0
+	}
+	return math.Round(1000*(float64(m.Wins) + float64(m.Draws)/2)/float64(m.Games))/10
+}
+
+func (m *Matchup) add(won int) {
+	m.Games++
+	switch {
+	case won > 0:
+		m.Wins++
+	case won < 0:
+		m.Losses++
+	default:
+		m.Draws++
+	}
+}
+
+func factions(list *ListInstance) []string {
+	return []string{list.List.Faction}
+}
+
+// ships gives the distinct ships in a list, in the order flown.
+func ships(list *ListInstance) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _,pilotinstance := range(list.List.Pilots) {
+		name := pilotinstance.Pilot.Chassis.Name
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Matchups tallies every game between two tabulated lists by faction
+// and by ship, from both sides.  Each distinct ship in one list is
+// matched against each distinct ship in the other.  Mirror matchups
+// are left out since they always even out, including a pair of ships
+// that both lists fly, which would otherwise be credited a win and a
+// loss from the one game.
+func (s *Stats) Matchups() []*Matchup {
+
+	matchups := make(map[[3]string]*Matchup)
+	var list []*Matchup
+
+	tally := func(kind string, sides func(*ListInstance) []string, m *MatchInstance) {
+		won := 0
+		switch m.Winner {
+		case 1:
+			won = 1
+		case 2:
+			won = -1
+		}
+		side1 := sides(m.List(1))
+		side2 := sides(m.List(2))
+		for _,a := range(side1) {
+			for _,b := range(side2) {
+				if a == b || (xwingdata.Contains(side2, a) && xwingdata.Contains(side1, b)) {
+					continue
+				}
+				for _,pair := range([][3]string{{kind, a, b}, {kind, b, a}}) {
+					matchup,ok := matchups[pair]
+					if !ok {
+						matchup = &Matchup{Kind: kind, Side: pair[1], Opponent: pair[2]}
+						matchups[pair] = matchup
+						list = append(list, matchup)
+					}
+					if pair[1] == a {
+						matchup.add(won)
+					} else {
+						matchup.add(-won)
+					}
+				}
+			}
+		}
+	}
+
+	for _,m := range(s.Matches) {
+		if !m.Played() {
+			continue
+		}
+		tally(MatchupFaction, factions, m)
+		tally(MatchupShip, ships, m)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		if list[i].Side != list[j].Side {
+			return list[i].Side < list[j].Side
+		}
+		return list[i].Opponent < list[j].Opponent
+	})
+
+	return list
+
+}
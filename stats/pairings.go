@@ -0,0 +1,230 @@
+package stats
+
+import (
+	"math"
+	"sort"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Kinds of pairings
+const (
+	PairingPilot = "Pilot"
+	PairingUpgrade = "Upgrade"
+)
+
+// Pairing is how often a pilot is fielded alongside another pilot, or
+// carrying an upgrade, and how those lists placed.  Lists count once
+// however many copies they fly.
+type Pairing struct {
+	Kind string
+	Pilot *xwingdata.Pilot
+
+	// The other pilot in the list, or the upgrade the pilot carries
+	Partner *xwingdata.Pilot
+	Upgrade *xwingdata.Upgrade
+
+	// Lists with both, with the pilot, and with the partner or upgrade
+	// anywhere, out of all the tabulated lists
+	Lists int
+	PilotLists int
+	PartnerLists int
+	TotalLists int
+
+	// Lists with both that have a placement, and their percentiles
+	Ranked int
+	SumPercentile float64
+}
+
+func (p *Pairing) AveragePercentile() float64 {
+	return average(p.SumPercentile, p.Ranked)
+}
+
+// Share is the percentage of the pilot's lists that include the
+// partner.
+func (p *Pairing) Share() float64 {
+	if p.PilotLists == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(p.Lists)/float64(p.PilotLists))/10
+}
+
+// Lift is how much more often the two appear together than they would
+// if chosen independently.  Above 1 they're deliberately paired.
+func (p *Pairing) Lift() float64 {
+	if p.PilotLists == 0 || p.PartnerLists == 0 {
+		return 0
+	}
+	lift := float64(p.Lists)*float64(p.TotalLists)/(float64(p.PilotLists)*float64(p.PartnerLists))
+	return math.Round(100*lift)/100
+}
+
+// reverse is a pilot pairing from the partner's side.
+func (p *Pairing) reverse() *Pairing {
+	r := *p
+	r.Pilot,r.Partner = p.Partner,p.Pilot
+	r.PilotLists,r.PartnerLists = p.PartnerLists,p.PilotLists
+	return &r
+}
+
+type Pairings struct {
+	// Every pairing, each pair of pilots once, most common first
+	Pairs []*Pairing
+
+	partners map[*xwingdata.Pilot][]*Pairing
+}
+
+// Partners gives the pilot's pairings of the given kind from its side,
+// most common first, at most n of them.
+func (p *Pairings) Partners(pilot *xwingdata.Pilot, kind string, n int) []*Pairing {
+	var list []*Pairing
+	for _,pairing := range(p.partners[pilot]) {
+		if pairing.Kind != kind {
+			continue
+		}
+		if len(list) >= n {
+			break
+		}
+		list = append(list, pairing)
+	}
+	return list
+}
+
+func sortpairings(list []*Pairing) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a := list[i]
+		b := list[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Lists != b.Lists {
+			return a.Lists > b.Lists
+		}
+		return a.Lift() > b.Lift()
+	})
+}
+
+// Pairings tallies every pair of different pilots fielded in the same
+// list, and every upgrade carried by each pilot, over all the
+// tabulated lists.  Pilots and upgrades left out of the outputs are
+// left out here too.
+func (s *Stats) Pairings() *Pairings {
+
+	x := s.Data.Exceptions
+
+	index := make(map[*xwingdata.Pilot]int)
+	for i,pilot := range(s.Data.Pilots) {
+		index[pilot] = i
+	}
+
+	pilotlists := make(map[*xwingdata.Pilot]int)
+	upgradelists := make(map[*xwingdata.Upgrade]int)
+
+	type pilotpair [2]*xwingdata.Pilot
+	type upgradepair struct {
+		pilot *xwingdata.Pilot
+		upgrade *xwingdata.Upgrade
+	}
+	pilotpairs := make(map[pilotpair]*Pairing)
+	upgradepairs := make(map[upgradepair]*Pairing)
+
+	var list []*Pairing
+
+	for _,l := range(s.Lists) {
+
+		percentile,ranked := l.Percentile()
+
+		var pilots []*xwingdata.Pilot
+		seenpilots := make(map[*xwingdata.Pilot]bool)
+		seenupgrades := make(map[*xwingdata.Upgrade]bool)
+		seenpairs := make(map[upgradepair]bool)
+
+		for _,pilotinstance := range(l.List.Pilots) {
+			pilot := pilotinstance.Pilot
+			if x.ExcludedPilot(pilot) {
+				continue
+			}
+			if !seenpilots[pilot] {
+				seenpilots[pilot] = true
+				pilots = append(pilots, pilot)
+				pilotlists[pilot]++
+			}
+
+			for _,upgrade := range(pilotinstance.UpgradeCards) {
+				if x.ExcludedUpgrade(upgrade) {
+					continue
+				}
+				if !seenupgrades[upgrade] {
+					seenupgrades[upgrade] = true
+					upgradelists[upgrade]++
+				}
+
+				key := upgradepair{pilot, upgrade}
+				if seenpairs[key] {
+					continue
+				}
+				seenpairs[key] = true
+
+				pairing,ok := upgradepairs[key]
+				if !ok {
+					pairing = &Pairing{Kind: PairingUpgrade, Pilot: pilot, Upgrade: upgrade}
+					upgradepairs[key] = pairing
+					list = append(list, pairing)
+				}
+				pairing.Lists++
+				if ranked {
+					pairing.Ranked++
+					pairing.SumPercentile += percentile
+				}
+			}
+		}
+
+		// Order each pair by the pilots' place in X-Wing Data so it is
+		// tallied the same way round in every list
+		sort.Slice(pilots, func(i, j int) bool {
+			return index[pilots[i]] < index[pilots[j]]
+		})
+		for i,a := range(pilots) {
+			for _,b := range(pilots[i+1:]) {
+				key := pilotpair{a, b}
+				pairing,ok := pilotpairs[key]
+				if !ok {
+					pairing = &Pairing{Kind: PairingPilot, Pilot: a, Partner: b}
+					pilotpairs[key] = pairing
+					list = append(list, pairing)
+				}
+				pairing.Lists++
+				if ranked {
+					pairing.Ranked++
+					pairing.SumPercentile += percentile
+				}
+			}
+		}
+
+	}
+
+	pairings := &Pairings{
+		Pairs: list,
+		partners: make(map[*xwingdata.Pilot][]*Pairing),
+	}
+
+	for _,pairing := range(list) {
+		pairing.TotalLists = len(s.Lists)
+		pairing.PilotLists = pilotlists[pairing.Pilot]
+		if pairing.Kind == PairingPilot {
+			pairing.PartnerLists = pilotlists[pairing.Partner]
+			pairings.partners[pairing.Partner] = append(pairings.partners[pairing.Partner], pairing.reverse())
+		} else {
+			pairing.PartnerLists = upgradelists[pairing.Upgrade]
+		}
+		pairings.partners[pairing.Pilot] = append(pairings.partners[pairing.Pilot], pairing)
+	}
+
+	sortpairings(pairings.Pairs)
+	for _,partners := range(pairings.partners) {
+		sortpairings(partners)
+	}
+
+	return pairings
+
+}
@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Performance summarizes how the lists flying a pilot or ship placed.
+// Each list counts once however many copies it flies.  Only the Ranked
+// lists, those with a placement, have a percentile to sum.
+type Performance struct {
+	Lists int
+	TopCuts int
+	Ranked int
+	SumPercentile float64
+}
+
+func (p *Performance) Add(list *ListInstance) {
+	p.Lists++
+	if list.TopCut() {
+		p.TopCuts++
+	}
+	if percentile,ok := list.Percentile(); ok {
+		p.Ranked++
+		p.SumPercentile += percentile
+	}
+}
+
+func round(x float64) float64 {
+	return math.Round(10*x)/10
+}
+
+// AveragePercentile is over the ranked lists.
+func (p *Performance) AveragePercentile() float64 {
+	if p.Ranked == 0 {
+		return 0
+	}
+	return round(p.SumPercentile/float64(p.Ranked))
+}
+
+// TopCutRate is the percentage of lists that made the cut.
+func (p *Performance) TopCutRate() float64 {
+	if p.Lists == 0 {
+		return 0
+	}
+	return round(100*float64(p.TopCuts)/float64(p.Lists))
+}
+
+// Index compares the lists' placing to what their usage would predict,
+// the average of all lists.  Above 1 they outperform their popularity,
+// below 1 they underperform it.  Only ranked lists are compared.
+func (p *Performance) Index(expected *Performance) float64 {
+	if p.Ranked == 0 || expected.SumPercentile == 0 {
+		return 0
+	}
+	share := p.SumPercentile/expected.SumPercentile
+	usage := float64(p.Ranked)/float64(expected.Ranked)
+	return math.Round(100*share/usage)/100
+}
+
+type Performances struct {
+	AllTime Performance
+	Recent Performance
+}
+
+func (p *Performances) Add(list *ListInstance, recent bool) {
+	p.AllTime.Add(list)
+	if recent {
+		p.Recent.Add(list)
+	}
+}
+
+// PilotPerformance returns how the lists flying a pilot placed, which
+// is empty if it has not been seen.
+func (s *Stats) PilotPerformance(pilot *xwingdata.Pilot) *Performances {
+	p,ok := s.PilotPerformances[pilot]
+	if !ok {
+		p = &Performances{}
+		s.PilotPerformances[pilot] = p
+	}
+	return p
+}
+
+func (s *Stats) ShipPerformance(ship *xwingdata.Ship) *Performances {
+	p,ok := s.ShipPerformances[ship]
+	if !ok {
+		p = &Performances{}
+		s.ShipPerformances[ship] = p
+	}
+	return p
+}
+
+// addperformance credits a list's placing to each pilot and ship it
+// flies, once each.
+func (s *Stats) addperformance(list *ListInstance, recent bool) {
+
+	s.Performance.Add(list, recent)
+
+	pilots := make(map[*xwingdata.Pilot]bool)
+	ships := make(map[*xwingdata.Ship]bool)
+	for _,pilotinstance := range(list.List.Pilots) {
+		pilot := pilotinstance.Pilot
+		if !pilots[pilot] {
+			pilots[pilot] = true
+			s.PilotPerformance(pilot).Add(list, recent)
+		}
+		if !ships[pilot.Chassis] {
+			ships[pilot.Chassis] = true
+			s.ShipPerformance(pilot.Chassis).Add(list, recent)
+		}
+	}
+
+}
@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlayerStats aggregates every tabulated list a player has flown.
+// Players are matched by name across tournaments, ignoring case.
+type PlayerStats struct {
+	Name string
+
+	Events int
+	FirstEvent string
+	LastEvent string
+	TopCuts int
+
+	// Lists flown per faction
+	Factions map[string]int
+
+	AveragePercentile float64
+
+	RecentEvents int
+	RecentAveragePercentile float64
+}
+
+// PlayerKey is how a player's name is matched across lists, matches,
+// and tournaments.
+func PlayerKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Players aggregates the lists by player, most events first.  Lists
+// without a player name are left out, and those without a placement
+// are left out of the average percentiles.
+func (s *Stats) Players() []*PlayerStats {
+
+	players := make(map[string]*PlayerStats)
+	var list []*PlayerStats
+
+	// Lists with a percentile to average, by player
+	ranked := make(map[*PlayerStats]int)
+	recentranked := make(map[*PlayerStats]int)
+
+	for _,l := range(s.Lists) {
+		key := PlayerKey(l.PlayerName)
+		if key == "" {
+			continue
+		}
+
+		p,ok := players[key]
+		if !ok {
+			p = &PlayerStats{
+				Name: strings.TrimSpace(l.PlayerName),
+				Factions: make(map[string]int),
+			}
+			players[key] = p
+			list = append(list, p)
+		}
+
+		p.Events++
+		p.Factions[l.List.Faction]++
+		if l.TopCut() {
+			p.TopCuts++
+		}
+		if p.FirstEvent == "" || l.EventDate < p.FirstEvent {
+			p.FirstEvent = l.EventDate
+		}
+		if l.EventDate > p.LastEvent {
+			p.LastEvent = l.EventDate
+		}
+
+		percentile,placed := l.Percentile()
+		if placed {
+			p.AveragePercentile += percentile
+			ranked[p]++
+		}
+
+		if date,err := time.Parse("2006-01-02", l.EventDate); err == nil && s.IsRecent(date) {
+			p.RecentEvents++
+			if placed {
+				p.RecentAveragePercentile += percentile
+				recentranked[p]++
+			}
+		}
+	}
+
+	for _,p := range(list) {
+		p.AveragePercentile = average(p.AveragePercentile, ranked[p])
+		p.RecentAveragePercentile = average(p.RecentAveragePercentile, recentranked[p])
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Events != list[j].Events {
+			return list[i].Events > list[j].Events
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+
+}
+
+// average divides a sum, rounded to one decimal place, or is zero if
+// there is nothing to average.
+func average(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return math.Round(10*sum/float64(n))/10
+}
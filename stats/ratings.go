@@ -0,0 +1,268 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+)
+
+// Kinds of ratings
+const (
+	RatingPlayer = "Player"
+	RatingArchetype = "Archetype"
+)
+
+// Ratings are updated once per calendar month, each month a Glicko-2
+// rating period.
+const RatingPeriodLayout = "2006-01"
+
+// Rated is a player's or archetype's rating at the end of a period,
+// with its games in that period, or in total for the current ratings.
+type Rated struct {
+	Kind string
+	Name string
+	Period string
+
+	Games int
+	Wins int
+	Losses int
+	Draws int
+
+	Glicko
+}
+
+func (r *Rated) add(score float64) {
+	r.Games++
+	switch score {
+	case 1:
+		r.Wins++
+	case 0:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+}
+
+type Ratings struct {
+	// Every rating as of the last period, most highly rated first
+	Current []*Rated
+
+	// Each rating after every period it played in, by period
+	History []*Rated
+}
+
+// rater tracks the ratings of one kind through the periods.
+type rater struct {
+	kind string
+	names map[string]string
+	ratings map[string]Glicko
+	last map[string]int
+	totals map[string]*Rated
+
+	// Games and results in the period being rated
+	games map[string][]glickoGame
+	period map[string]*Rated
+}
+
+func newrater(kind string) *rater {
+	return &rater{
+		kind: kind,
+		names: make(map[string]string),
+		ratings: make(map[string]Glicko),
+		last: make(map[string]int),
+		totals: make(map[string]*Rated),
+	}
+}
+
+// rating returns a rating as of the start of period p, growing its
+// deviation for the periods it sat out.
+func (r *rater) rating(key string, p int) Glicko {
+	g,ok := r.ratings[key]
+	if !ok {
+		return NewGlicko()
+	}
+	for i := r.last[key]+1; i < p; i++ {
+		g = g.Idle()
+	}
+	return g
+}
+
+func (r *rater) game(p int, a string, b string, names [2]string, score float64) {
+
+	if a == b {
+		return
+	}
+
+	keys := [2]string{a, b}
+	scores := [2]float64{score, 1-score}
+	ratings := [2]Glicko{r.rating(a, p), r.rating(b, p)}
+
+	for i,key := range(keys) {
+		if _,ok := r.names[key]; !ok {
+			r.names[key] = names[i]
+			r.totals[key] = &Rated{Kind: r.kind, Name: names[i]}
+		}
+		if r.period[key] == nil {
+			r.period[key] = &Rated{Kind: r.kind, Name: r.names[key]}
+		}
+
+		r.games[key] = append(r.games[key], glickoGame{ratings[1-i], scores[i]})
+		r.period[key].add(scores[i])
+		r.totals[key].add(scores[i])
+	}
+
+}
+
+func (r *rater) begin() {
+	r.games = make(map[string][]glickoGame)
+	r.period = make(map[string]*Rated)
+}
+
+// end rates everything that played in period p.
+func (r *rater) end(p int, name string) []*Rated {
+
+	var rated []*Rated
+	for key,games := range(r.games) {
+		g := r.rating(key, p).Update(games)
+		r.ratings[key] = g
+		r.last[key] = p
+
+		x := r.period[key]
+		x.Period = name
+		x.Glicko = g.Rounded()
+		rated = append(rated, x)
+	}
+	return rated
+
+}
+
+// current gives every rating as of period p.
+func (r *rater) current(p int, names []string) []*Rated {
+
+	var rated []*Rated
+	for key,x := range(r.totals) {
+		x.Period = names[r.last[key]]
+		x.Glicko = r.rating(key, p+1).Rounded()
+		rated = append(rated, x)
+	}
+	return rated
+
+}
+
+func monthindex(date time.Time) int {
+	return 12*date.Year() + int(date.Month()) - 1
+}
+
+// Ratings runs Glicko-2 over every reported game in date order, rating
+// players, matched by name, and list archetypes.  Byes, games in
+// tournaments without a parseable date, and mirror archetype games
+// are left out, as are games against players who aren't among the
+// tournament's players, and for the archetype ratings games against
+// lists that weren't tabulated.
+func (s *Stats) Ratings() *Ratings {
+
+	type dated struct {
+		month int
+		match *MatchInstance
+	}
+
+	var matches []dated
+	for _,m := range(s.Matches) {
+		if m.Result == listjuggler.MatchBye || strings.TrimSpace(m.Players[0]) == "" || strings.TrimSpace(m.Players[1]) == "" || m.Unknown[0] || m.Unknown[1] {
+			continue
+		}
+		date,err := time.Parse("2006-01-02", m.EventDate)
+		if err != nil {
+			continue
+		}
+		matches = append(matches, dated{monthindex(date), m})
+	}
+
+	ratings := &Ratings{}
+	if len(matches) == 0 {
+		return ratings
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].month < matches[j].month
+	})
+	first := matches[0].month
+
+	var names []string
+	for month := first; month <= matches[len(matches)-1].month; month++ {
+		names = append(names, time.Date(month/12, time.Month(month%12 + 1), 1, 0, 0, 0, 0, time.UTC).Format(RatingPeriodLayout))
+	}
+
+	clusters := s.Archetypes()
+
+	players := newrater(RatingPlayer)
+	archetypes := newrater(RatingArchetype)
+	raters := []*rater{players, archetypes}
+
+	for i := 0; i < len(matches); {
+
+		p := matches[i].month - first
+		for _,r := range(raters) {
+			r.begin()
+		}
+
+		for ; i < len(matches) && matches[i].month - first == p; i++ {
+			m := matches[i].match
+
+			score := 0.5
+			switch m.Winner {
+			case 1:
+				score = 1
+			case 2:
+				score = 0
+			}
+
+			players.game(p, PlayerKey(m.Players[0]), PlayerKey(m.Players[1]),
+				[2]string{strings.TrimSpace(m.Players[0]), strings.TrimSpace(m.Players[1])}, score)
+
+			if m.Played() {
+				a := clusters.Name(m.List(1))
+				b := clusters.Name(m.List(2))
+				archetypes.game(p, a, b, [2]string{a, b}, score)
+			}
+		}
+
+		for _,r := range(raters) {
+			ratings.History = append(ratings.History, r.end(p, names[p])...)
+		}
+
+	}
+
+	for _,r := range(raters) {
+		ratings.Current = append(ratings.Current, r.current(len(names)-1, names)...)
+	}
+
+	sort.Slice(ratings.History, func(i, j int) bool {
+		a := ratings.History[i]
+		b := ratings.History[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	sort.Slice(ratings.Current, func(i, j int) bool {
+		a := ratings.Current[i]
+		b := ratings.Current[j]
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return a.Name < b.Name
+	})
+
+	return ratings
+
+}
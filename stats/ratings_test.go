@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func match(date string, a string, b string, winner int) *MatchInstance {
+	result := listjuggler.MatchWin
+	if winner == 0 {
+		result = listjuggler.MatchDraw
+	}
+	return &MatchInstance{
+		EventDate: date,
+		Players: [2]string{a, b},
+		Result: result,
+		Winner: winner,
+		Lists: [2]int{-1, -1},
+	}
+}
+
+func TestRatings(t *testing.T) {
+
+	bye := match("2016-01-09", "Alice", "", 1)
+	bye.Result = listjuggler.MatchBye
+
+	unknown := match("2016-01-09", "Alice", "Eve", 1)
+	unknown.Unknown[1] = true
+
+	cases := []struct {
+		name string
+		matches []*MatchInstance
+		current []Rated
+		history int
+	}{
+		{
+			"Win",
+			[]*MatchInstance{match("2016-01-09", "Alice", "Bob", 1)},
+			[]Rated{
+				{Kind: RatingPlayer, Name: "Alice", Period: "2016-01", Games: 1, Wins: 1, Glicko: Glicko{1662.3, 290.3, 0.06}},
+				{Kind: RatingPlayer, Name: "Bob", Period: "2016-01", Games: 1, Losses: 1, Glicko: Glicko{1337.7, 290.3, 0.06}},
+			},
+			2,
+		},
+		{
+			"Names matched ignoring case and spacing",
+			[]*MatchInstance{
+				match("2016-01-09", "Alice", "Bob", 0),
+				match("2016-01-16", " alice", "BOB ", 0),
+			},
+			[]Rated{
+				{Kind: RatingPlayer, Name: "Alice", Period: "2016-01", Games: 2, Draws: 2, Glicko: Glicko{1500, 253.4, 0.059998}},
+				{Kind: RatingPlayer, Name: "Bob", Period: "2016-01", Games: 2, Draws: 2, Glicko: Glicko{1500, 253.4, 0.059998}},
+			},
+			2,
+		},
+		{
+			"Deviation grows while idle",
+			[]*MatchInstance{
+				match("2016-03-05", "Carol", "Dave", 2),
+				match("2016-01-09", "Alice", "Bob", 1),
+			},
+			[]Rated{
+				{Kind: RatingPlayer, Name: "Alice", Period: "2016-01", Games: 1, Wins: 1, Glicko: Glicko{1662.3, 290.7, 0.06}},
+				{Kind: RatingPlayer, Name: "Dave", Period: "2016-03", Games: 1, Wins: 1, Glicko: Glicko{1662.3, 290.3, 0.06}},
+				{Kind: RatingPlayer, Name: "Bob", Period: "2016-01", Games: 1, Losses: 1, Glicko: Glicko{1337.7, 290.7, 0.06}},
+				{Kind: RatingPlayer, Name: "Carol", Period: "2016-03", Games: 1, Losses: 1, Glicko: Glicko{1337.7, 290.3, 0.06}},
+			},
+			4,
+		},
+		{
+			"Unknown players left out",
+			[]*MatchInstance{unknown},
+			nil,
+			0,
+		},
+		{
+			"Byes and undated games left out",
+			[]*MatchInstance{bye, match("sometime", "Alice", "Bob", 1)},
+			nil,
+			0,
+		},
+	}
+
+	for _,c := range(cases) {
+
+		st := New(&xwingdata.Data{}, DefaultOptions())
+		st.Matches = c.matches
+
+		ratings := st.Ratings()
+		if len(ratings.History) != c.history {
+			t.Errorf("%v: %v ratings in the history, expected %v", c.name, len(ratings.History), c.history)
+		}
+		if len(ratings.Current) != len(c.current) {
+			t.Errorf("%v: %v current ratings, expected %v", c.name, len(ratings.Current), len(c.current))
+			continue
+		}
+		for i,expected := range(c.current) {
+			if *ratings.Current[i] != expected {
+				t.Errorf("%v: rating %v is %+v, expected %+v", c.name, i, *ratings.Current[i], expected)
+			}
+		}
+
+	}
+
+}
@@ -0,0 +1,192 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Eligible counts the lists flying a pilot or ship among those that
+// could have: lists of its factions from tournaments on or after its
+// release.  Uses before the release aren't counted here but are noted
+// as data quality issues.
+type Eligible struct {
+	Lists int
+	Eligible int
+}
+
+// Share is the percentage of eligible lists flying the pilot or ship.
+func (e *Eligible) Share() float64 {
+	if e.Eligible == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(e.Lists)/float64(e.Eligible))/10
+}
+
+// Eligibility is a pilot's or ship's use since its release, for all
+// time and recently.  Without a known release every list of its
+// factions is eligible.
+type Eligibility struct {
+	AllTime Eligible
+	Recent Eligible
+}
+
+type eligibilities struct {
+	pilots map[*xwingdata.Pilot]*Eligibility
+	ships map[*xwingdata.Ship]*Eligibility
+}
+
+// PilotEligibility gives a pilot's use among the lists since its
+// release.
+func (s *Stats) PilotEligibility(pilot *xwingdata.Pilot) *Eligibility {
+	if e,ok := s.eligibilities().pilots[pilot]; ok {
+		return e
+	}
+	return &Eligibility{}
+}
+
+// ShipEligibility gives a ship's use among the lists since its
+// release.
+func (s *Stats) ShipEligibility(ship *xwingdata.Ship) *Eligibility {
+	if e,ok := s.eligibilities().ships[ship]; ok {
+		return e
+	}
+	return &Eligibility{}
+}
+
+// since counts the sorted dates on or after a release date.
+func since(dates []string, release string) int {
+	return len(dates) - sort.SearchStrings(dates, release)
+}
+
+func (s *Stats) eligibilities() *eligibilities {
+
+	if s.eligible != nil {
+		return s.eligible
+	}
+
+	e := &eligibilities{
+		pilots: make(map[*xwingdata.Pilot]*Eligibility),
+		ships: make(map[*xwingdata.Ship]*Eligibility),
+	}
+	s.eligible = e
+
+	// The dates of every list, and of the recent ones, by faction
+	alltime := make(map[string][]string)
+	recent := make(map[string][]string)
+
+	for _,l := range(s.Lists) {
+
+		date,err := time.Parse("2006-01-02", l.EventDate)
+		if err != nil {
+			continue
+		}
+		day := date.Format("2006-01-02")
+
+		faction,err := xwingdata.FactionMap(l.List.Faction)
+		if err != nil {
+			continue
+		}
+		alltime[faction] = append(alltime[faction], day)
+		isrecent := s.IsRecent(date)
+		if isrecent {
+			recent[faction] = append(recent[faction], day)
+		}
+
+		pilots := make(map[*xwingdata.Pilot]bool)
+		ships := make(map[*xwingdata.Ship]bool)
+		for _,pilotinstance := range(l.List.Pilots) {
+			pilot := pilotinstance.Pilot
+			if !pilots[pilot] && day >= pilot.Release.Date {
+				pilots[pilot] = true
+				e.pilot(pilot).add(isrecent)
+			}
+			ship := pilot.Chassis
+			if !ships[ship] && day >= ship.Release.Date {
+				ships[ship] = true
+				e.ship(ship).add(isrecent)
+			}
+		}
+
+	}
+
+	for _,dates := range([]map[string][]string{alltime, recent}) {
+		for _,d := range(dates) {
+			sort.Strings(d)
+		}
+	}
+
+	for _,pilot := range(s.Data.Pilots) {
+		faction,err := xwingdata.FactionMap(pilot.Faction)
+		if err != nil {
+			continue
+		}
+		p := e.pilot(pilot)
+		p.AllTime.Eligible = since(alltime[faction], pilot.Release.Date)
+		p.Recent.Eligible = since(recent[faction], pilot.Release.Date)
+	}
+
+	for _,ship := range(s.Data.Ships) {
+		factions,err := ship.Factions()
+		if err != nil {
+			continue
+		}
+		sh := e.ship(ship)
+		for _,faction := range(factions) {
+			sh.AllTime.Eligible += since(alltime[faction], ship.Release.Date)
+			sh.Recent.Eligible += since(recent[faction], ship.Release.Date)
+		}
+	}
+
+	return e
+
+}
+
+func (e *eligibilities) pilot(pilot *xwingdata.Pilot) *Eligibility {
+	p,ok := e.pilots[pilot]
+	if !ok {
+		p = &Eligibility{}
+		e.pilots[pilot] = p
+	}
+	return p
+}
+
+func (e *eligibilities) ship(ship *xwingdata.Ship) *Eligibility {
+	sh,ok := e.ships[ship]
+	if !ok {
+		sh = &Eligibility{}
+		e.ships[ship] = sh
+	}
+	return sh
+}
+
+func (e *Eligibility) add(recent bool) {
+	e.AllTime.Lists++
+	if recent {
+		e.Recent.Lists++
+	}
+}
+
+// prerelease notes each pilot and ship in a list flown before its
+// release, which the eligible counts leave out.
+func (s *Stats) prerelease(list *ListInstance, day string) {
+	noted := make(map[string]bool)
+	note := func(kind string, code string, release xwingdata.Release) {
+		if day >= release.Date || noted[kind + code] {
+			return
+		}
+		noted[kind + code] = true
+		s.Issues = append(s.Issues, &Issue{
+			Kind: kind,
+			Detail: code + " available since " + release.Date,
+			Tournament: list.EventID,
+		})
+	}
+	for _,pilotinstance := range(list.List.Pilots) {
+		pilot := pilotinstance.Pilot
+		note("Pilot used before release", pilot.Code, pilot.Release)
+		note("Ship used before release", pilot.Chassis.XWS, pilot.Chassis.Release)
+	}
+}
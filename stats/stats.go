@@ -0,0 +1,632 @@
+// Package stats tabulates pilot and upgrade usage from ListJuggler
+// tournament reports resolved against X-Wing Data.
+package stats
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+	"github.com/RocketshipGames/xwing-csv/listjuggler"
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+const DogfightFormat = "standard - 100 point dogfight"
+
+// Options selects which tournaments are tabulated and which count as
+// recent.
+type Options struct {
+
+	// Tournament formats and scopes to include, compared case
+	// insensitively.  No scopes means all scopes.
+	Formats []string
+	Scopes []string
+
+	// Windows are resolved against AsOf, or if it is zero the date of
+	// the latest tabulated tournament.  Tournaments in the Recent window
+	// are counted as recent, and the extra Windows each count their
+	// own uses.
+	AsOf time.Time
+	Recent Window
+	Windows []Window
+
+	// Quarantine lists and tournaments that can't be resolved or
+	// tabulated, noting the issue, rather than failing the compile
+	Lenient bool
+
+	// Use the best suggested alias for an unknown pilot or ship if it
+	// scores at least this, from 0 to 1.  Zero never does.
+	AutoAlias float64
+
+	// Lists are clustered into archetypes when at least
+	// ArchetypeThreshold similar, from 0 to 1, by the named
+	// ArchetypeSimilarity, with the ArchetypeUpgrades XWS codes counted
+	// alongside the pilots
+	ArchetypeSimilarity string
+	ArchetypeThreshold float64
+	ArchetypeUpgrades []string
+
+	// Time series and archetypes over time are bucketed by the named
+	// period
+	Period string
+
+}
+
+// DefaultOptions tabulates dogfight tournaments of every scope, with
+// the four months up to the latest tournament counting as recent, and
+// clusters lists by Jaccard similarity.
+func DefaultOptions() Options {
+	return Options{
+		Formats: []string{DogfightFormat},
+		Recent: Window{Name: "Recent", Months: 4},
+		ArchetypeSimilarity: DefaultSimilarity,
+		ArchetypeThreshold: DefaultArchetypeThreshold,
+		Period: DefaultPeriod,
+	}
+}
+
+// TournamentID identifies a tournament by its report's file name,
+// which is its ListJuggler ID.
+func TournamentID(file string) string {
+	return strings.TrimSuffix(path.Base(file), ".json")
+}
+
+func includes(list []string, s string) bool {
+	for _,x := range(list) {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Options) IncludesFormat(format string) bool {
+	return includes(o.Formats, format)
+}
+
+func (o *Options) IncludesScope(scope string) bool {
+	return len(o.Scopes) == 0 || includes(o.Scopes, scope)
+}
+
+// Stats accumulates everything compiled from the tournament reports.
+type Stats struct {
+	Data *xwingdata.Data
+	Options Options
+
+	AllTime DataCounts
+	Recent DataCounts
+
+	Pilots map[*xwingdata.Pilot]*Counts
+	Upgrades map[*xwingdata.Upgrade]*Counts
+
+	// How lists placed overall, and those flying each pilot and ship
+	Performance Performances
+	PilotPerformances map[*xwingdata.Pilot]*Performances
+	ShipPerformances map[*xwingdata.Ship]*Performances
+
+	// Every tournament report, whether or not it was included
+	Tournaments []*TournamentInstance
+	Lists []*ListInstance
+	RejectedLists []*RejectedList
+
+	// Games reported in the tabulated tournaments' rounds
+	Matches []*MatchInstance
+
+	UnknownUpgrades map[string]int
+	Issues []*Issue
+
+	// Candidate aliases for unknown pilots and ships, by code
+	Suggestions map[string]*Suggestion
+
+	// Processed tournaments from previous compiles, nil to disable
+	Cache *Cache
+
+	// Clustered once the lists are all tabulated
+	archetypes *Archetypes
+
+	// Use since release, counted once the lists are all tabulated
+	eligible *eligibilities
+
+	// The reference date and the windows resolved against it
+	AsOf time.Time
+	recent span
+	windows []span
+}
+
+func New(data *xwingdata.Data, options Options) *Stats {
+	return &Stats{
+		Data: data,
+		Options: options,
+		Pilots: make(map[*xwingdata.Pilot]*Counts),
+		Upgrades: make(map[*xwingdata.Upgrade]*Counts),
+		PilotPerformances: make(map[*xwingdata.Pilot]*Performances),
+		ShipPerformances: make(map[*xwingdata.Ship]*Performances),
+		Lists: make([]*ListInstance, 0),
+		RejectedLists: make([]*RejectedList, 0),
+		UnknownUpgrades: make(map[string]int),
+		Suggestions: make(map[string]*Suggestion),
+	}
+}
+
+// Pilot returns the usage counts for a pilot, which are zero if it has
+// not been seen.
+func (s *Stats) Pilot(pilot *xwingdata.Pilot) *Counts {
+	c,ok := s.Pilots[pilot]
+	if !ok {
+		c = &Counts{}
+		s.Pilots[pilot] = c
+	}
+	return c
+}
+
+func (s *Stats) Upgrade(upgrade *xwingdata.Upgrade) *Counts {
+	c,ok := s.Upgrades[upgrade]
+	if !ok {
+		c = &Counts{}
+		s.Upgrades[upgrade] = c
+	}
+	return c
+}
+
+// ReadTournaments tabulates every tournament report in folder.  If the
+// stats have a cache, reports that haven't changed since they were
+// cached are tabulated from it instead of being processed again.
+func (s *Stats) ReadTournaments(folder string, parent *logberry.Task) error {
+
+	task := parent.Task("Get tournament stats")
+	
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return task.WrapError("Could not read tournaments folder", err)
+	}
+
+	cached := 0
+	seen := make(map[string]bool)
+	var results []*TournamentResult
+	for _, file := range(files) {
+
+		// Skip the downloader's index and anything else not a report
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		name := filepath.Join(folder, file.Name())
+		seen[name] = true
+
+		bits, err := ioutil.ReadFile(name)
+		if err != nil {
+			return task.Error(err)
+		}
+		hash := fetch.ContentHash(bits)
+
+		result := s.Cache.Get(name, hash, s.Data)
+		if result != nil {
+			cached++
+		} else {
+			result,err = s.ProcessTournament(name, bits, task)
+			if err != nil {
+				return task.Error(err)
+			}
+			result.SHA256 = hash
+			s.Cache.Put(name, result)
+		}
+
+		results = append(results, result)
+	}
+
+	s.Cache.Prune(seen)
+
+	// The windows can only be placed once every tournament's date is
+	// known
+	asof := s.Options.AsOf
+	if asof.IsZero() {
+		asof = latest(results)
+	}
+	err = s.SetAsOf(asof)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	for _,result := range(results) {
+		err = s.Tabulate(result, task)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+	
+	return task.Success(logberry.D{"Files": len(files), "Cached": cached, "AsOf": asof.Format("2006-01-02")})
+
+}
+
+// TournamentResult is everything learned from one tournament report
+// that doesn't depend on the recent window, so it can be cached.
+type TournamentResult struct {
+	SHA256 string
+	File string
+	Date string
+	Scope string
+	Tournament *TournamentInstance
+	Lists []*ListInstance
+	RejectedLists []*RejectedList
+	Matches []*MatchInstance
+	UnknownUpgrades map[string]int
+	Issues []*Issue
+	Suggestions []*Suggestion
+
+	// Why the whole tournament was set aside, if it was
+	Quarantined string
+}
+
+func (r *TournamentResult) issue(kind string, detail string) {
+	r.Issues = append(r.Issues, &Issue{
+		Kind: kind,
+		Detail: detail,
+		Tournament: TournamentID(r.File),
+	})
+}
+
+func (r *TournamentResult) skip(reason string) {
+	r.Tournament.Status = TournamentSkipped
+	r.Tournament.Reason = reason
+}
+
+// quarantine sets the whole tournament aside, noting the issue.
+func (r *TournamentResult) quarantine(reason string, detail string) {
+	r.Quarantined = reason
+	r.Tournament.Status = TournamentQuarantined
+	r.Tournament.Reason = reason
+	r.issue(reason, detail)
+}
+
+func (r *TournamentResult) rejectlist(tournament *listjuggler.Tournament, list *listjuggler.List, reason string) {
+	r.RejectedLists = append(r.RejectedLists, &RejectedList{
+		File: r.File,
+		EventDate: tournament.Date,
+		EventScope: tournament.Scope,
+		Reason: reason,
+		List: list,
+	})
+}
+
+// ResolveError is a list that can't be matched against X-Wing Data.
+type ResolveError struct {
+	Kind string
+	Detail string
+
+	// Candidate aliases for the unknown code, if there are any
+	Suggestion *Suggestion
+}
+
+func (e *ResolveError) Error() string {
+	return e.Kind + " " + e.Detail
+}
+
+// Resolve matches a list's pilots and upgrades against X-Wing Data,
+// returning the codes of any unknown upgrades and the suggested aliases
+// applied to unknown pilots and ships.  Unknown upgrades are noted but
+// don't invalidate the list.
+func (s *Stats) Resolve(list *listjuggler.List, parent *logberry.Task) ([]string,[]*Suggestion,*ResolveError) {
+
+	var unknown []string
+	var aliased []*Suggestion
+
+	for _,pilotinstance := range(list.Pilots) {
+		xws,err := s.Data.Exceptions.PilotMap(list.Faction, pilotinstance.Ship, pilotinstance.XWS)
+		if err != nil {
+			return nil,nil,&ResolveError{Kind: "Unknown faction", Detail: list.Faction}
+		}
+
+		pilot,ok := s.Data.PilotsXWS[xws]
+		if !ok {
+			var applied []*Suggestion
+			var rerr *ResolveError
+			pilot,xws,applied,rerr = s.alias(xws)
+			if rerr != nil {
+				return nil,nil,rerr
+			}
+			for _,suggestion := range(applied) {
+				parent.Warning("Applied suggested alias", logberry.D{"Code": suggestion.Code, "Alias": suggestion.Best()})
+			}
+			aliased = append(aliased, applied...)
+		}
+
+		pilotinstance.Pilot = pilot
+		pilotinstance.PilotCode = xws
+
+		pilotinstance.UpgradeCards = nil
+		pilotinstance.UpgradeCodes = nil
+		for _,slot := range(pilotinstance.Upgrades.Slots()) {
+			for _,name := range(slot.XWS) {
+				code := xwingdata.UpgradeMap(slot.Slot, name)
+				upgrade,ok := s.Data.UpgradesXWS[code]
+				if !ok {
+					parent.Warning("Unknown upgrade", logberry.D{"XWS": code})
+					unknown = append(unknown, code)
+					continue
+				}
+				pilotinstance.UpgradeCards = append(pilotinstance.UpgradeCards, upgrade)
+				pilotinstance.UpgradeCodes = append(pilotinstance.UpgradeCodes, code)
+			}
+		}
+	}
+
+	return unknown,aliased,nil
+
+}
+
+// ProcessTournament filters and resolves the lists in a tournament
+// report without tabulating them.
+func (s *Stats) ProcessTournament(file string, bits []byte, parent *logberry.Task) (*TournamentResult,error) {
+
+	task := parent.Task("Process tournament", logberry.D{"File": file})
+
+	result := &TournamentResult{
+		File: file,
+		UnknownUpgrades: make(map[string]int),
+	}
+
+	result.Tournament = &TournamentInstance{ID: TournamentID(file)}
+
+	tournament, err := listjuggler.ParseTournament(bits, task)
+	if err != nil {
+		if !s.Options.Lenient {
+			return nil,task.Error(err)
+		}
+		result.quarantine("Unreadable report", err.Error())
+		return result,task.Success()
+	}
+	result.Date = tournament.Date
+	result.Scope = tournament.Scope
+
+	*result.Tournament = TournamentInstance{
+		ID: result.Tournament.ID,
+		Name: tournament.Name,
+		Date: tournament.Date,
+		Scope: tournament.Scope,
+		Format: tournament.Format,
+		Venue: tournament.Venue.Name,
+		City: tournament.Venue.City,
+		State: tournament.Venue.State,
+		Country: tournament.Venue.Country,
+		DeclaredPlayers: tournament.PlayerCount,
+		ReportedPlayers: len(tournament.Players),
+		RoundLength: tournament.RoundDuration,
+	}
+
+	// Bail if there are no players reported
+	if len(tournament.Players) <= 0 {
+		task.Warning("Tournament has no players")
+		result.skip("No players")
+		return result,task.Success()
+	}
+	
+	// Some tournaments don't report lists for all players, others don't
+	// fill in the player count explicitly
+	if tournament.PlayerCount < len(tournament.Players) {
+		if tournament.PlayerCount != 0 {
+			task.Warning("Under-reported player count", logberry.D{"Count": tournament.PlayerCount, "Reported": len(tournament.Players)})
+			result.issue("Under-reported player count", fmt.Sprintf("%v declared, %v reported", tournament.PlayerCount, len(tournament.Players)))
+		}
+		tournament.PlayerCount = len(tournament.Players)
+	}
+	result.Tournament.Players = tournament.PlayerCount
+
+	// Only tabulate the selected kinds of tournaments
+	if !s.Options.IncludesFormat(tournament.Format) {
+		task.Warning("Format not included", tournament.Format)
+		result.skip("Format not included")
+		return result,task.Success()
+	}
+
+	if !s.Options.IncludesScope(tournament.Scope) {
+		task.Warning("Scope not included", tournament.Scope)
+		result.skip("Scope not included")
+		return result,task.Success()
+	}
+
+	if _,err := time.Parse("2006-01-02", tournament.Date); err != nil {
+		if !s.Options.Lenient {
+			return nil,task.Failure("Unparseable tournament date", tournament.Date)
+		}
+		result.quarantine("Unparseable date", tournament.Date)
+		return result,task.Success()
+	}
+
+	if !ValidScope(tournament.Scope) {
+		if !s.Options.Lenient {
+			return nil,task.Failure("Unknown tournament scope", tournament.Scope)
+		}
+		result.quarantine("Unknown scope", tournament.Scope)
+		return result,task.Success()
+	}
+	
+	// For each player in the tournament	
+	for _,player := range(tournament.Players) {
+
+		// No list reported
+		if player.List == nil {
+			continue
+		}
+
+		if len(player.List.Pilots) <= 0 {
+			task.Warning("Player has list but no pilots")
+			result.rejectlist(tournament, player.List, "No pilots")
+			continue
+		}
+
+		unknown,aliased,rerr := s.Resolve(player.List, task)
+		if rerr != nil {
+			if rerr.Suggestion != nil {
+				result.Suggestions = append(result.Suggestions, rerr.Suggestion)
+			}
+			if !s.Options.Lenient {
+				d := logberry.D{"XWS": rerr.Detail}
+				if rerr.Suggestion != nil {
+					d["Suggestions"] = rerr.Suggestion.Candidates
+				}
+				return nil,task.Failure(rerr.Kind, d)
+			}
+			result.issue(rerr.Kind, rerr.Detail)
+			result.rejectlist(tournament, player.List, rerr.Kind)
+			continue
+		}
+		for _,suggestion := range(aliased) {
+			result.Suggestions = append(result.Suggestions, suggestion)
+			result.issue("Applied suggested alias", suggestion.Code + " as " + suggestion.Best())
+		}
+		for _,code := range(unknown) {
+			result.UnknownUpgrades[code]++
+			result.issue("Unknown upgrade", code)
+		}
+
+		// Check that the list is a valid dogfight list
+		ships,upgrades := player.List.Points(s.Data.Exceptions)
+		if ships+upgrades > 100 {
+			task.Warning("List was over 100 points", logberry.D{"Ships": ships, "Upgrades": upgrades})
+			result.rejectlist(tournament, player.List, "Over 100 points")
+			continue
+		}
+
+		/*
+		if points < 50 {
+			task.Warning("List spent under 50 points on ships", points)
+			continue
+		}
+    */
+		
+		// Create a list record
+		listinstance := ListInstance{
+			EventID: result.Tournament.ID,
+			EventCountry: tournament.Venue.Country,
+			EventState: tournament.Venue.State,
+			EventScope: tournament.Scope,
+			EventDate: tournament.Date,
+			EventPlayers: tournament.PlayerCount,
+			EventRank: player.Rank.Swiss,
+			EventElimination: player.Rank.Elimination,
+			PlayerName: player.Name,
+			PlayerID: player.ID,
+			List: player.List,
+		}
+		result.Lists = append(result.Lists, &listinstance)
+		
+	}
+
+	result.addmatches(tournament)
+
+	return result,task.Success()
+
+}
+
+// Tabulate adds a processed tournament to the usage counts.  The
+// reference date must have been set.
+func (s *Stats) Tabulate(result *TournamentResult, parent *logberry.Task) error {
+
+	task := parent.Task("Tabulate tournament", logberry.D{"File": result.File})
+
+	s.RejectedLists = append(s.RejectedLists, result.RejectedLists...)
+	for code,count := range(result.UnknownUpgrades) {
+		s.UnknownUpgrades[code] += count
+	}
+	s.Issues = append(s.Issues, result.Issues...)
+	s.Tournaments = append(s.Tournaments, result.Tournament)
+	for _,suggestion := range(result.Suggestions) {
+		s.addsuggestion(suggestion)
+	}
+
+	if result.Quarantined != "" {
+		task.Warning("Tournament quarantined", result.Quarantined)
+		return task.Success()
+	}
+
+	// Only count tournaments that actually reported players with valid lists
+	if result.Tournament.Status == TournamentSkipped {
+		return task.Success()
+	}
+	result.Tournament.Lists = len(result.Lists)
+	if len(result.Lists) <= 0 {
+		task.Warning("No lists reported")
+		result.skip("No lists")
+		return task.Success()
+	}
+	result.Tournament.Status = TournamentIncluded
+
+	// Determine whether or not this is a recent tournament, and which
+	// windows it falls in
+	recent := false
+	windows := make([]bool, len(s.windows))
+	day := ""
+	date, err := time.Parse("2006-01-02", result.Date)
+	if err == nil {
+		day = date.Format("2006-01-02")
+		if s.IsRecent(date) {
+			recent = true
+			task.Warning("Recent event!")
+		}
+		windows = s.InWindows(date)
+	}
+
+	for _,listinstance := range(result.Lists) {
+
+		if day != "" {
+			s.prerelease(listinstance, day)
+		}
+
+		// Update stats for this player's pilots and their upgrades
+		for _,pilotinstance := range(listinstance.List.Pilots) {
+
+			err = s.Pilot(pilotinstance.Pilot).Increment(result.Scope, recent, windows)
+			if err != nil {
+				return task.Error(err)
+			}
+
+			s.AllTime.PilotInstances++
+			if recent {
+				s.Recent.PilotInstances++
+			}
+
+			for _,upgrade := range(pilotinstance.UpgradeCards) {
+				err = s.Upgrade(upgrade).Increment(result.Scope, recent, windows)
+				if err != nil {
+					return task.Error(err)
+				}
+
+				s.AllTime.UpgradeInstances++
+				if recent {
+					s.Recent.UpgradeInstances++
+				}
+			}
+
+		}
+		
+		// Increment number of player lists reported
+		if recent {
+			s.Recent.ListInstances++
+		}
+		s.AllTime.ListInstances++
+
+		s.addperformance(listinstance, recent)
+
+		s.Lists = append(s.Lists, listinstance)
+
+	}
+
+	for _,match := range(result.Matches) {
+		match.link(result.Lists)
+		s.Matches = append(s.Matches, match)
+	}
+
+	if recent {
+		s.Recent.Tournaments++
+	}
+	s.AllTime.Tournaments++
+	
+	return task.Success()
+	
+}
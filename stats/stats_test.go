@@ -0,0 +1,37 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/BellerophonMobile/logberry"
+)
+
+func TestUnparseableDate(t *testing.T) {
+
+	report := []byte(`{"tournament": {
+		"date": "sometime",
+		"type": "Store Championship",
+		"format": "Standard - 100 Point Dogfight",
+		"players": [
+			{"name": "Alice", "list": {"faction": "rebel", "pilots": [{"name": "wedgeantilles", "ship": "xwing"}]}}
+		]
+	}}`)
+
+	_,err := New(cachedata(), DefaultOptions()).ProcessTournament("106.json", report, logberry.Main)
+	if err == nil {
+		t.Errorf("Strict mode accepted a tournament with an unparseable date")
+	}
+
+	options := DefaultOptions()
+	options.Lenient = true
+	st := New(cachedata(), options)
+
+	result,err := st.ProcessTournament("106.json", report, logberry.Main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tournament.Status != TournamentQuarantined || len(result.Lists) != 0 {
+		t.Errorf("Lenient mode left the tournament %v with %v lists, expected it quarantined", result.Tournament.Status, len(result.Lists))
+	}
+
+}
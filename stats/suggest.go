@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// At most SuggestionLimit candidates scoring at least SuggestionMinimum
+// are suggested for each unknown pilot or ship.
+const SuggestionLimit = 3
+const SuggestionMinimum = 0.5
+
+// Suggestion is a set of candidate aliases for an unknown pilot or
+// ship code, as they would be entered in the exceptions.
+type Suggestion struct {
+
+	// "ShipAliases" or "PilotAliases"
+	Exception string
+
+	// The fully qualified unknown code, and the part of it an alias
+	// would map from
+	Code string
+	From string
+
+	Candidates []xwingdata.Candidate
+
+	// Whether the best candidate was used in place of the unknown code
+	Applied bool
+
+	// Lists in which the code was seen, filled in when tabulated
+	Count int `json:"-"`
+
+}
+
+// Best returns the highest scoring candidate's XWS code, if there is one.
+func (s *Suggestion) Best() string {
+	if len(s.Candidates) == 0 {
+		return ""
+	}
+	return s.Candidates[0].XWS
+}
+
+// apply marks the suggestion applied if its best candidate scores at
+// least the AutoAlias option and is not tied with the next best.
+func (s *Stats) apply(suggestion *Suggestion) bool {
+
+	c := suggestion.Candidates
+	suggestion.Applied = s.Options.AutoAlias > 0 &&
+		len(c) > 0 && c[0].Score >= s.Options.AutoAlias &&
+		(len(c) == 1 || c[1].Score < c[0].Score)
+
+	return suggestion.Applied
+
+}
+
+// alias finds candidates for a pilot code missing from X-Wing Data,
+// first for its ship if that is unknown too.  It returns the pilot and
+// its code if the suggestions could be applied.
+func (s *Stats) alias(code string) (*xwingdata.Pilot,string,[]*Suggestion,*ResolveError) {
+
+	var applied []*Suggestion
+
+	i := strings.LastIndex(code, "/")
+	shipcode, pilotxws := code[:i], code[i+1:]
+
+	if _,ok := s.Data.ShipsXWS[shipcode]; !ok {
+		j := strings.Index(shipcode, "/")
+		suggestion := &Suggestion{
+			Exception: "ShipAliases",
+			Code: shipcode,
+			From: shipcode[j+1:],
+			Candidates: s.Data.SuggestShips(shipcode[:j], shipcode[j+1:], SuggestionLimit, SuggestionMinimum),
+		}
+		if !s.apply(suggestion) {
+			return nil,"",nil,&ResolveError{"Unknown ship", shipcode, suggestion}
+		}
+		applied = append(applied, suggestion)
+
+		shipcode = suggestion.Candidates[0].Code
+		code = shipcode + "/" + pilotxws
+		if pilot,ok := s.Data.PilotsXWS[code]; ok {
+			return pilot,code,applied,nil
+		}
+	}
+
+	suggestion := &Suggestion{
+		Exception: "PilotAliases",
+		Code: code,
+		From: pilotxws,
+		Candidates: s.Data.SuggestPilots(shipcode, pilotxws, SuggestionLimit, SuggestionMinimum),
+	}
+	if !s.apply(suggestion) {
+		return nil,"",nil,&ResolveError{"Unknown pilot", code, suggestion}
+	}
+	applied = append(applied, suggestion)
+
+	code = suggestion.Candidates[0].Code
+	return s.Data.PilotsXWS[code],code,applied,nil
+
+}
+
+func (s *Stats) addsuggestion(suggestion *Suggestion) {
+	existing,ok := s.Suggestions[suggestion.Code]
+	if !ok {
+		existing = suggestion
+		existing.Count = 0
+		s.Suggestions[suggestion.Code] = existing
+	}
+	existing.Count++
+}
+
+// SortedSuggestions returns the suggestions by exception and code.
+func (s *Stats) SortedSuggestions() []*Suggestion {
+
+	list := make([]*Suggestion, 0, len(s.Suggestions))
+	for _,suggestion := range(s.Suggestions) {
+		list = append(list, suggestion)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Exception != list[j].Exception {
+			return list[i].Exception > list[j].Exception
+		}
+		return list[i].Code < list[j].Code
+	})
+
+	return list
+
+}
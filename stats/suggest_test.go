@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func TestApplySuggestion(t *testing.T) {
+
+	candidates := func(scores ...float64) []xwingdata.Candidate {
+		var list []xwingdata.Candidate
+		for _,score := range(scores) {
+			list = append(list, xwingdata.Candidate{Score: score})
+		}
+		return list
+	}
+
+	cases := []struct {
+		name string
+		autoalias float64
+		candidates []xwingdata.Candidate
+		applied bool
+	}{
+		{"Disabled", 0, candidates(1), false},
+		{"No candidates", 0.9, nil, false},
+		{"Single candidate", 0.9, candidates(0.95), true},
+		{"Best ahead", 0.9, candidates(0.95, 0.9), true},
+		{"Best tied", 0.9, candidates(0.95, 0.95), false},
+		{"Best tied below the rest", 0.9, candidates(0.95, 0.95, 0.6), false},
+		{"Below the threshold", 0.9, candidates(0.85), false},
+		{"At the threshold", 0.9, candidates(0.9), true},
+	}
+
+	for _,c := range(cases) {
+		options := DefaultOptions()
+		options.AutoAlias = c.autoalias
+		st := New(&xwingdata.Data{}, options)
+
+		suggestion := &Suggestion{Candidates: c.candidates}
+		if applied := st.apply(suggestion); applied != c.applied || suggestion.Applied != c.applied {
+			t.Errorf("%v: applied %v, expected %v", c.name, applied, c.applied)
+		}
+	}
+
+}
+
+func TestAlias(t *testing.T) {
+
+	xwing := &xwingdata.Ship{Name: "X-Wing", XWS: "xwing"}
+	ywing := &xwingdata.Ship{Name: "Y-Wing", XWS: "ywing"}
+	wedge := &xwingdata.Pilot{Name: "Wedge Antilles", XWS: "wedgeantilles", Code: "rebel/xwing/wedgeantilles", Chassis: xwing}
+	horton := &xwingdata.Pilot{Name: "Horton Salm", XWS: "hortonsalm", Code: "rebel/ywing/hortonsalm", Chassis: ywing}
+
+	data := &xwingdata.Data{
+		ShipsXWS: map[string]*xwingdata.Ship{"rebel/xwing": xwing, "rebel/ywing": ywing},
+		PilotsXWS: map[string]*xwingdata.Pilot{wedge.Code: wedge, horton.Code: horton},
+	}
+
+	cases := []struct {
+		code string
+		pilot *xwingdata.Pilot
+		kind string
+	}{
+		{"rebel/xwing/wedgeantiles", wedge, ""},
+		{"rebel/ywing/hortonslm", horton, ""},
+		{"rebel/xwing/darthvader", nil, "Unknown pilot"},
+
+		// Equally close to the X-Wing and Y-Wing
+		{"rebel/awing/hortonsalm", nil, "Unknown ship"},
+	}
+
+	options := DefaultOptions()
+	options.AutoAlias = 0.8
+	st := New(data, options)
+
+	for _,c := range(cases) {
+		pilot,_,_,rerr := st.alias(c.code)
+		kind := ""
+		if rerr != nil {
+			kind = rerr.Kind
+			if rerr.Suggestion.Applied {
+				t.Errorf("%v: unresolved but the suggestion is marked applied", c.code)
+			}
+		}
+		if pilot != c.pilot || kind != c.kind {
+			t.Errorf("%v: aliased to %v with error %q, expected %v with %q", c.code, pilot, kind, c.pilot, c.kind)
+		}
+	}
+
+}
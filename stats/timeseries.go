@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+// Periods bucket dates for the time series, named so they sort in
+// order.
+var Periods = map[string]func(time.Time) string{
+	"week": func(date time.Time) string {
+		year,week := date.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	},
+	"month": func(date time.Time) string {
+		return date.Format("2006-01")
+	},
+	"quarter": func(date time.Time) string {
+		return fmt.Sprintf("%04d-Q%d", date.Year(), (int(date.Month())+2)/3)
+	},
+	"year": func(date time.Time) string {
+		return date.Format("2006")
+	},
+}
+
+const DefaultPeriod = "month"
+
+// PeriodOf names the period a date falls in.
+func (o *Options) PeriodOf(date time.Time) string {
+	period := Periods[o.Period]
+	if period == nil {
+		period = Periods[DefaultPeriod]
+	}
+	return period(date)
+}
+
+// Kinds of time series
+const (
+	SeriesFaction = "Faction"
+	SeriesArchetype = "Archetype"
+	SeriesShip = "Ship"
+	SeriesPilot = "Pilot"
+)
+
+var serieskinds = []string{SeriesFaction, SeriesArchetype, SeriesShip, SeriesPilot}
+
+// SeriesPoint is how many lists in a period flew a faction, archetype,
+// ship, or pilot, and how many copies of the ship or pilot they flew.
+type SeriesPoint struct {
+	Period string
+	Kind string
+	Name string
+	XWS string
+
+	Lists int
+	Copies int
+
+	// All the tabulated lists in the period
+	TotalLists int
+}
+
+// Share is the percentage of the period's lists.
+func (p *SeriesPoint) Share() float64 {
+	if p.TotalLists == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(p.Lists)/float64(p.TotalLists))/10
+}
+
+// TimeSeries buckets the tabulated lists by period, giving a point for
+// everything flown in each period.  Lists without a valid date and
+// pilots left out of the outputs are left out.
+func (s *Stats) TimeSeries() []*SeriesPoint {
+
+	archetypes := s.Archetypes()
+
+	type key struct {
+		period string
+		kind string
+		name string
+	}
+	points := make(map[key]*SeriesPoint)
+	totals := make(map[string]int)
+	var list []*SeriesPoint
+
+	point := func(period string, kind string, name string, xws string) *SeriesPoint {
+		k := key{period, kind, name}
+		p,ok := points[k]
+		if !ok {
+			p = &SeriesPoint{Period: period, Kind: kind, Name: name, XWS: xws}
+			points[k] = p
+			list = append(list, p)
+		}
+		return p
+	}
+
+	for _,l := range(s.Lists) {
+
+		date,err := time.Parse("2006-01-02", l.EventDate)
+		if err != nil {
+			continue
+		}
+		period := s.Options.PeriodOf(date)
+		totals[period]++
+
+		f := point(period, SeriesFaction, l.List.Faction, l.List.Faction)
+		f.Lists++
+		f.Copies++
+
+		if name := archetypes.Name(l); name != "" {
+			a := point(period, SeriesArchetype, name, "")
+			a.Lists++
+			a.Copies++
+		}
+
+		ships := make(map[*xwingdata.Ship]bool)
+		pilots := make(map[*xwingdata.Pilot]bool)
+		for _,pilotinstance := range(l.List.Pilots) {
+			pilot := pilotinstance.Pilot
+			if s.Data.Exceptions.ExcludedPilot(pilot) {
+				continue
+			}
+
+			ship := point(period, SeriesShip, pilot.Chassis.Name, pilot.Chassis.XWS)
+			ship.Copies++
+			if !ships[pilot.Chassis] {
+				ships[pilot.Chassis] = true
+				ship.Lists++
+			}
+
+			p := point(period, SeriesPilot, s.Data.PilotLabel(pilot), pilot.XWS)
+			p.Copies++
+			if !pilots[pilot] {
+				pilots[pilot] = true
+				p.Lists++
+			}
+		}
+
+	}
+
+	order := make(map[string]int)
+	for i,kind := range(serieskinds) {
+		order[kind] = i
+	}
+
+	for _,p := range(list) {
+		p.TotalLists = totals[p.Period]
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a := list[i]
+		b := list[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Kind != b.Kind {
+			return order[a.Kind] < order[b.Kind]
+		}
+		if a.Lists != b.Lists {
+			return a.Lists > b.Lists
+		}
+		return a.Name < b.Name
+	})
+
+	return list
+
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+)
+
+var scopes = []string{
+	"world championship",
+	"nationals",
+	"regional",
+	"store championship",
+	"vassal play",
+	"other",
+}
+
+// ValidScope reports whether uses can be counted for a tournament scope.
+func ValidScope(scope string) bool {
+	return includes(scopes, scope)
+}
+
+type Uses struct {
+	Total int
+	Worlds int
+	Nationals int
+	Regionals int
+	Stores int
+	Vassals int
+	Other int
+}
+
+func (x *Uses) Increment(scope string) error {
+			
+	x.Total++
+	
+	switch strings.ToLower(scope) {
+	case "world championship":
+		x.Worlds++
+	case "nationals":
+		x.Nationals++
+	case "regional":
+		x.Regionals++
+	case "store championship":
+		x.Stores++
+	case "vassal play":
+		x.Vassals++
+	case "other":
+		x.Other++				
+	default:
+		return fmt.Errorf("Unknown tournament scope %v", scope)
+	}
+
+	return nil
+
+}
+
+// Counts tracks the uses of one pilot or upgrade.
+type Counts struct {
+	AllTime Uses
+	Recent Uses
+
+	// Uses in each of the extra windows, in order
+	Windows []Uses
+}
+
+func (c *Counts) Increment(scope string, recent bool, windows []bool) error {
+
+	err := c.AllTime.Increment(scope)
+	if err != nil {
+		return err
+	}
+
+	if recent {
+		err = c.Recent.Increment(scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i,in := range(windows) {
+		if !in {
+			continue
+		}
+		for len(c.Windows) <= i {
+			c.Windows = append(c.Windows, Uses{})
+		}
+		err = c.Windows[i].Increment(scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// Window returns the uses in the i'th extra window.
+func (c *Counts) Window(i int) Uses {
+	if i < len(c.Windows) {
+		return c.Windows[i]
+	}
+	return Uses{}
+}
+
+type DataCounts struct {
+	Tournaments int
+	ListInstances int
+	PilotInstances int
+	UpgradeInstances int
+}
@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a named span of tournament dates.  A window of Months
+// reaches back that many months from the reference date, otherwise it
+// starts at From.  It runs up to To, or through the reference date if
+// To is zero.
+type Window struct {
+	Name string
+	Months int
+	From time.Time
+	To time.Time
+}
+
+// Span resolves the window against the reference date, giving its
+// start, inclusive, and end, exclusive.
+func (w *Window) Span(asof time.Time) (time.Time,time.Time) {
+	from, to := w.From, w.To
+	if w.Months > 0 {
+		from = asof.AddDate(0,-w.Months,0)
+	}
+	if to.IsZero() {
+		to = asof.AddDate(0,0,1)
+	}
+	return from,to
+}
+
+// ParseWindow reads a window given as name=spec, where spec is a
+// number of months, a start date, or a start and end date separated by
+// "..", such as "Last 3 Months=3" or "Wave X=2017-01-26".
+func ParseWindow(s string) (Window,error) {
+
+	var w Window
+
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return w,fmt.Errorf("Window %v must be name=months or name=date", s)
+	}
+	w.Name = strings.TrimSpace(s[:i])
+	spec := strings.TrimSpace(s[i+1:])
+
+	if w.Name == "All Time" || w.Name == "Recent" {
+		return w,fmt.Errorf("Window name %v is reserved", w.Name)
+	}
+
+	if months,err := strconv.Atoi(spec); err == nil {
+		if months <= 0 {
+			return w,fmt.Errorf("Window %v must span a positive number of months", w.Name)
+		}
+		w.Months = months
+		return w,nil
+	}
+
+	from, to := spec, ""
+	if j := strings.Index(spec, ".."); j >= 0 {
+		from, to = spec[:j], spec[j+2:]
+	}
+
+	var err error
+	w.From,err = time.Parse("2006-01-02", from)
+	if err != nil {
+		return w,fmt.Errorf("Bad start date %v for window %v", from, w.Name)
+	}
+
+	if to != "" {
+		w.To,err = time.Parse("2006-01-02", to)
+		if err != nil {
+			return w,fmt.Errorf("Bad end date %v for window %v", to, w.Name)
+		}
+		if !w.To.After(w.From) {
+			return w,fmt.Errorf("Window %v ends before it starts", w.Name)
+		}
+	}
+
+	return w,nil
+
+}
+
+type span struct {
+	from time.Time
+	to time.Time
+}
+
+func (s span) includes(date time.Time) bool {
+	return !date.Before(s.from) && date.Before(s.to)
+}
+
+// SetAsOf resolves the recent and extra windows against the reference
+// date.  It must be called before tabulating.  A window given an end
+// that isn't after its resolved start, such as months back from a
+// reference date long after the end, is an error.
+func (s *Stats) SetAsOf(asof time.Time) error {
+	s.AsOf = asof
+
+	resolve := func(w *Window) (span,error) {
+		from,to := w.Span(asof)
+		if !w.To.IsZero() && !to.After(from) {
+			return span{},fmt.Errorf("Window %v ends %v, not after it starts %v", w.Name, to.Format("2006-01-02"), from.Format("2006-01-02"))
+		}
+		return span{from, to},nil
+	}
+
+	var err error
+	s.recent,err = resolve(&s.Options.Recent)
+	if err != nil {
+		return err
+	}
+
+	s.windows = nil
+	for i := range(s.Options.Windows) {
+		w,err := resolve(&s.Options.Windows[i])
+		if err != nil {
+			return err
+		}
+		s.windows = append(s.windows, w)
+	}
+
+	return nil
+}
+
+func (s *Stats) IsRecent(date time.Time) bool {
+	return s.recent.includes(date)
+}
+
+// InWindows reports whether a date is in each of the extra windows.
+func (s *Stats) InWindows(date time.Time) []bool {
+	in := make([]bool, len(s.windows))
+	for i,w := range(s.windows) {
+		in[i] = w.includes(date)
+	}
+	return in
+}
+
+// latest finds the date of the most recent tournament that will be
+// tabulated, zero if there are none.
+func latest(results []*TournamentResult) time.Time {
+	var asof time.Time
+	for _,result := range(results) {
+		if result.Quarantined != "" || result.Tournament.Status == TournamentSkipped || len(result.Lists) == 0 {
+			continue
+		}
+		date,err := time.Parse("2006-01-02", result.Date)
+		if err == nil && date.After(asof) {
+			asof = date
+		}
+	}
+	return asof
+}
@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RocketshipGames/xwing-csv/xwingdata"
+)
+
+func date(s string) time.Time {
+	d,err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParseWindow(t *testing.T) {
+
+	cases := []struct {
+		spec string
+		expected Window
+		fails bool
+	}{
+		{"Last 3 Months=3", Window{Name: "Last 3 Months", Months: 3}, false},
+		{" Wave X = 2017-01-26 ", Window{Name: "Wave X", From: date("2017-01-26")}, false},
+		{"Wave X=2017-01-26..2017-06-01", Window{Name: "Wave X", From: date("2017-01-26"), To: date("2017-06-01")}, false},
+		{"A=B=1", Window{Name: "A=B", Months: 1}, false},
+		{"3", Window{}, true},
+		{"=3", Window{}, true},
+		{"Never=0", Window{}, true},
+		{"Backwards=-2", Window{}, true},
+		{"Recent=3", Window{}, true},
+		{"All Time=3", Window{}, true},
+		{"Bad=2017-02-30", Window{}, true},
+		{"Bad=2017-01-26..soon", Window{}, true},
+		{"Empty=2017-01-26..2017-01-26", Window{}, true},
+		{"Reversed=2017-06-01..2017-01-26", Window{}, true},
+	}
+
+	for _,c := range(cases) {
+		w,err := ParseWindow(c.spec)
+		if (err != nil) != c.fails {
+			t.Errorf("Parsing %q gave error %v", c.spec, err)
+			continue
+		}
+		if !c.fails && (w.Name != c.expected.Name || w.Months != c.expected.Months || !w.From.Equal(c.expected.From) || !w.To.Equal(c.expected.To)) {
+			t.Errorf("Parsed %q as %+v, expected %+v", c.spec, w, c.expected)
+		}
+	}
+
+}
+
+func TestSetAsOf(t *testing.T) {
+
+	cases := []struct {
+		name string
+		window Window
+		asof string
+		in []string
+		out []string
+		fails bool
+	}{
+		{
+			"Months back through the reference date",
+			Window{Name: "Recent", Months: 4},
+			"2016-12-20",
+			[]string{"2016-08-20", "2016-10-01", "2016-12-20"},
+			[]string{"2016-08-19", "2016-12-21"},
+			false,
+		},
+		{
+			"Months back from the 31st overflow a short month, as time.AddDate does",
+			Window{Name: "Recent", Months: 1},
+			"2016-03-31",
+			[]string{"2016-03-02", "2016-03-31"},
+			[]string{"2016-03-01", "2016-02-29"},
+			false,
+		},
+		{
+			"From a date through the reference date",
+			Window{Name: "Wave X", From: date("2016-11-01")},
+			"2016-12-20",
+			[]string{"2016-11-01", "2016-12-20"},
+			[]string{"2016-10-31", "2016-12-21"},
+			false,
+		},
+		{
+			"A date range ends before its end date",
+			Window{Name: "Wave X", From: date("2016-11-01"), To: date("2016-12-01")},
+			"2016-12-20",
+			[]string{"2016-11-01", "2016-11-30"},
+			[]string{"2016-10-31", "2016-12-01"},
+			false,
+		},
+		{
+			"Months ending before the reference date",
+			Window{Name: "Recent", Months: 2, To: date("2016-12-01")},
+			"2016-12-20",
+			[]string{"2016-10-20", "2016-11-30"},
+			[]string{"2016-10-19", "2016-12-01"},
+			false,
+		},
+		{
+			"Months ending before they start",
+			Window{Name: "Recent", Months: 2, To: date("2016-10-01")},
+			"2016-12-20",
+			nil,
+			nil,
+			true,
+		},
+		{
+			"Starting after the reference date",
+			Window{Name: "Wave X", From: date("2017-01-26")},
+			"2016-12-20",
+			nil,
+			[]string{"2016-12-20", "2017-01-26"},
+			false,
+		},
+	}
+
+	for _,c := range(cases) {
+
+		for _,recent := range([]bool{true, false}) {
+
+			options := DefaultOptions()
+			if recent {
+				options.Recent = c.window
+			} else {
+				options.Windows = []Window{c.window}
+			}
+			st := New(&xwingdata.Data{}, options)
+
+			err := st.SetAsOf(date(c.asof))
+			if (err != nil) != c.fails {
+				t.Errorf("%v: resolving gave error %v", c.name, err)
+				continue
+			}
+
+			includes := func(d string) bool {
+				if recent {
+					return st.IsRecent(date(d))
+				}
+				return st.InWindows(date(d))[0]
+			}
+			for _,d := range(c.in) {
+				if !includes(d) {
+					t.Errorf("%v: %v isn't in the window", c.name, d)
+				}
+			}
+			for _,d := range(c.out) {
+				if includes(d) {
+					t.Errorf("%v: %v is in the window", c.name, d)
+				}
+			}
+
+		}
+
+	}
+
+}
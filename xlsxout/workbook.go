@@ -0,0 +1,210 @@
+// Package xlsxout writes the compiled X-Wing Data and ListJuggler
+// statistics as a spreadsheet workbook.
+package xlsxout
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/BellerophonMobile/logberry"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/RocketshipGames/xwing-csv/csvout"
+	"github.com/RocketshipGames/xwing-csv/stats"
+)
+
+//
+// The workbook has a sheet for each of the main tables, filled from the
+// same rows as the CSV outputs but with numbers kept as numbers, each
+// with its header frozen and filterable.  A summary sheet gives the
+// overall counts.
+//
+
+var SummaryColumns = []string{
+	"Count",
+	"All Time",
+	"Recent",
+}
+
+func SummaryRows(rows csvout.Rows, st *stats.Stats) error {
+
+	for _,count := range([]struct{
+		name string
+		alltime int
+		recent int
+	}{
+		{"Tournaments", st.AllTime.Tournaments, st.Recent.Tournaments},
+		{"Lists", st.AllTime.ListInstances, st.Recent.ListInstances},
+		{"Pilots", st.AllTime.PilotInstances, st.Recent.PilotInstances},
+		{"Upgrades", st.AllTime.UpgradeInstances, st.Recent.UpgradeInstances},
+	}) {
+		err := rows.Write(count.name, count.alltime, count.recent)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// sheet streams rows into a worksheet.
+type sheet struct {
+	stream *excelize.StreamWriter
+	columns int
+	rows int
+}
+
+// cell keeps numbers as numbers, including named types such as
+// xwingdata.Int, and leaves empty strings as empty cells.
+func cell(v interface{}) interface{} {
+
+	if s,ok := v.(string); ok {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return r.Int()
+	case reflect.Float32, reflect.Float64:
+		return r.Float()
+	}
+
+	return v
+
+}
+
+func (s *sheet) Write(values ...interface{}) error {
+
+	row := csvout.Flatten(values...)
+	if len(row) != s.columns {
+		return fmt.Errorf("Row has %v fields but the sheet has %v columns", len(row), s.columns)
+	}
+
+	for i,v := range(row) {
+		row[i] = cell(v)
+	}
+
+	s.rows++
+	name, err := excelize.CoordinatesToCellName(1, s.rows+1)
+	if err != nil {
+		return err
+	}
+
+	return s.stream.SetRow(name, row)
+
+}
+
+func writesheet(f *excelize.File, name string, columns []string, header int, rows func(csvout.Rows) error) error {
+
+	stream, err := f.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+
+	err = stream.SetPanes(&excelize.Panes{
+		Freeze: true,
+		YSplit: 1,
+		TopLeftCell: "A2",
+		ActivePane: "bottomLeft",
+	})
+	if err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(columns))
+	for i,c := range(columns) {
+		cells[i] = excelize.Cell{StyleID: header, Value: c}
+	}
+	err = stream.SetRow("A1", cells)
+	if err != nil {
+		return err
+	}
+
+	s := &sheet{stream: stream, columns: len(columns)}
+	err = rows(s)
+	if err != nil {
+		return err
+	}
+
+	// Streamed sheets can only be filtered as a table, which needs at
+	// least one row besides the header
+	if s.rows > 0 {
+		last, err := excelize.CoordinatesToCellName(len(columns), s.rows+1)
+		if err != nil {
+			return err
+		}
+
+		err = stream.AddTable(&excelize.Table{
+			Range: "A1:" + last,
+			Name: name,
+			StyleName: "TableStyleLight1",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return stream.Flush()
+
+}
+
+func WriteWorkbook(file string, st *stats.Stats, parent *logberry.Task) error {
+
+	task := parent.Task("Write workbook", logberry.D{"File": file})
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return task.Error(err)
+	}
+
+	sheets := []struct{
+		name string
+		columns []string
+		rows func(csvout.Rows) error
+	}{
+		{"Summary", SummaryColumns, func(rows csvout.Rows) error {
+			return SummaryRows(rows, st)
+		}},
+		{"Ships", csvout.AllShipColumns(st), func(rows csvout.Rows) error {
+			return csvout.ShipRows(rows, st)
+		}},
+		{"Pilots", csvout.AllPilotColumns(st), func(rows csvout.Rows) error {
+			return csvout.PilotRows(rows, st)
+		}},
+		{"Lists", csvout.ListColumns, func(rows csvout.Rows) error {
+			return csvout.ListRows(rows, st, task)
+		}},
+	}
+
+	for i,s := range(sheets) {
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), s.name)
+		} else {
+			_, err = f.NewSheet(s.name)
+		}
+		if err != nil {
+			return task.Error(err)
+		}
+
+		err = writesheet(f, s.name, s.columns, header, s.rows)
+		if err != nil {
+			return task.WrapError("Could not write sheet", err, logberry.D{"Sheet": s.name})
+		}
+	}
+
+	err = f.SaveAs(file)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	return task.Success(logberry.D{"Sheets": len(sheets)})
+
+}
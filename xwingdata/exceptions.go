@@ -0,0 +1,209 @@
+package xwingdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BellerophonMobile/logberry"
+)
+
+//
+// Various exceptions are required to make the different data sources
+// together.  These are kept in ExceptionsFile so they can be updated
+// without changing the code.  Search for "EXCEPTIONS" to find where
+// they are applied.
+//
+
+const ExceptionsFile = "exceptions.json"
+const ExceptionsVersion = 1
+
+type Replacement struct {
+	From string
+	To string
+}
+
+type PilotShip struct {
+	Name string
+	XWS string
+}
+
+type UpgradeDiscount struct {
+	Upgrade string
+	Slot string
+	Discount int
+}
+
+type Exceptions struct {
+	Version int
+
+	// Substring replacements and whole-code aliases applied to ship
+	// codes before matching
+	ShipReplacements []Replacement
+	ShipAliases map[string]string
+
+	// Aliases applied to pilot codes before matching, and pilots that
+	// are actually on a different ship than listed
+	PilotAliases map[string]string
+	PilotShips map[string]PilotShip
+
+	// Ships missing from X-Wing Data
+	Ships []*Ship
+
+	// Ship sizes, pilots, and upgrade slots left out of the outputs
+	ExcludedSizes []string
+	ExcludedPilots []string
+	ExcludedUpgradeSlots []string
+
+	// Upgrades that reduce the cost of the other upgrades on their ship
+	UpgradeDiscounts []UpgradeDiscount
+
+	// Duplicately named pilots labeled by faction rather than ship
+	FactionLabels []string
+}
+
+func LoadExceptions(file string, parent *logberry.Task) (*Exceptions,error) {
+
+	task := parent.Task("Load exceptions", logberry.D{"File": file})
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+	defer f.Close()
+
+	var x Exceptions
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&x)
+	if err != nil {
+		return nil,task.WrapError("Could not unmarshal exceptions", err)
+	}
+
+	err = x.validate()
+	if err != nil {
+		return nil,task.WrapError("Invalid exceptions", err)
+	}
+
+	return &x,task.Success(logberry.D{"Version": x.Version})
+
+}
+
+func (x *Exceptions) validate() error {
+
+	if x.Version != ExceptionsVersion {
+		return fmt.Errorf("Unsupported version %v, expected %v", x.Version, ExceptionsVersion)
+	}
+
+	for _,r := range(x.ShipReplacements) {
+		if r.From == "" {
+			return fmt.Errorf("Empty ship replacement")
+		}
+	}
+
+	aliases := map[string]map[string]string{
+		"Ship": x.ShipAliases,
+		"Pilot": x.PilotAliases,
+	}
+	for kind,m := range(aliases) {
+		for from,to := range(m) {
+			if from != strings.ToLower(from) || to != strings.ToLower(to) || to == "" {
+				return fmt.Errorf("%v alias %v => %v must be non-empty lower case", kind, from, to)
+			}
+		}
+	}
+
+	for _,ship := range(x.Ships) {
+		if ship.Name == "" || ship.XWS == "" {
+			return fmt.Errorf("Ship must have a name and XWS %v", ship)
+		}
+		if len(ship.Faction) == 0 {
+			return fmt.Errorf("Ship %v has no faction", ship.Name)
+		}
+		if _,err := ship.Factions(); err != nil {
+			return err
+		}
+		if ship.Size != "small" && ship.Size != "large" && ship.Size != "huge" {
+			return fmt.Errorf("Ship %v has unknown size %v", ship.Name, ship.Size)
+		}
+	}
+
+	for pilot,ship := range(x.PilotShips) {
+		if pilot != strings.ToLower(pilot) || ship.Name == "" || ship.XWS == "" {
+			return fmt.Errorf("Pilot ship %v must be lower case with a ship name and XWS", pilot)
+		}
+	}
+
+	for _,d := range(x.UpgradeDiscounts) {
+		if !strings.Contains(d.Upgrade, "/") || d.Discount <= 0 {
+			return fmt.Errorf("Upgrade discount %v must name a slot/upgrade code and a positive discount", d.Upgrade)
+		}
+	}
+
+	return nil
+
+}
+
+// UpgradePoints sums the cost of the upgrades equipped on one ship,
+// including the discounts some titles apply to the other upgrades.
+func (x *Exceptions) UpgradePoints(upgrades []*Upgrade) int {
+
+	// BEGIN EXCEPTIONS
+	var discounts []UpgradeDiscount
+	for _,upgrade := range(upgrades) {
+		for _,d := range(x.UpgradeDiscounts) {
+			if d.Upgrade == upgrade.Code {
+				discounts = append(discounts, d)
+			}
+		}
+	}
+	// END EXCEPTIONS
+
+	points := 0
+	for _,upgrade := range(upgrades) {
+		cost := int(upgrade.Points)
+
+		discount := 0
+		for _,d := range(discounts) {
+			if d.Upgrade != upgrade.Code && (d.Slot == "" || d.Slot == upgrade.Slot) {
+				discount += d.Discount
+			}
+		}
+
+		// Discounts go to a minimum of zero, so they never lower cards
+		// like Chardaan Refit that have a negative cost of their own
+		if discount > 0 {
+			cost = max(cost-discount, min(cost, 0))
+		}
+
+		points += cost
+	}
+	return points
+
+}
+
+// ExcludedPilot reports whether a pilot is left out of the outputs.
+func (x *Exceptions) ExcludedPilot(pilot *Pilot) bool {
+	// BEGIN EXCEPTIONS
+	return Contains(x.ExcludedSizes, pilot.Chassis.Size) ||
+		Contains(x.ExcludedPilots, pilot.XWS)
+	// END EXCEPTIONS
+}
+
+// ExcludedUpgrade reports whether an upgrade is left out of the
+// outputs.
+func (x *Exceptions) ExcludedUpgrade(upgrade *Upgrade) bool {
+	// BEGIN EXCEPTIONS
+	return Contains(x.ExcludedUpgradeSlots, upgrade.Slot)
+	// END EXCEPTIONS
+}
+
+func Contains(list []string, s string) bool {
+	for _,x := range(list) {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package xwingdata
+
+import (
+	"testing"
+)
+
+func TestUpgradePoints(t *testing.T) {
+
+	x := &Exceptions{
+		UpgradeDiscounts: []UpgradeDiscount{
+			{Upgrade: "title/vaksai", Discount: 1},
+			{Upgrade: "title/tiex1", Slot: "System", Discount: 4},
+		},
+	}
+
+	vaksai := &Upgrade{Slot: "Title", Points: 0, Code: "title/vaksai"}
+	tiex1 := &Upgrade{Slot: "Title", Points: 0, Code: "title/tiex1"}
+	pushthelimit := &Upgrade{Slot: "Elite", Points: 3, Code: "elite/pushthelimit"}
+	fcs := &Upgrade{Slot: "System", Points: 2, Code: "system/firecontrolsystem"}
+	accuracycorrector := &Upgrade{Slot: "System", Points: 3, Code: "system/accuracycorrector"}
+	chardaanrefit := &Upgrade{Slot: "Missile", Points: -2, Code: "missile/chardaanrefit"}
+
+	cases := []struct {
+		name string
+		upgrades []*Upgrade
+		points int
+	}{
+		{"No upgrades", nil, 0},
+		{"No discount", []*Upgrade{pushthelimit, fcs}, 5},
+		{"Negative cost", []*Upgrade{pushthelimit, chardaanrefit}, 1},
+		{"Discount", []*Upgrade{vaksai, pushthelimit, fcs}, 3},
+		{"Discount limited to a slot", []*Upgrade{tiex1, pushthelimit, accuracycorrector}, 3},
+		{"Discount stops at zero", []*Upgrade{tiex1, fcs}, 0},
+		{"Discounted negative cost", []*Upgrade{vaksai, chardaanrefit}, -2},
+	}
+
+	for _,c := range(cases) {
+		if points := x.UpgradePoints(c.upgrades); points != c.points {
+			t.Errorf("%v: upgrades cost %v, expected %v", c.name, points, c.points)
+		}
+	}
+
+}
@@ -0,0 +1,93 @@
+package xwingdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XWS upgrade type keys mapped to the X-Wing Data slot names
+var UpgradeTypes = map[string]string{
+	"ept": "Elite",
+	"amd": "Astromech",
+	"samd": "Salvaged Astromech",
+	"crew": "Crew",
+	"system": "System",
+	"tech": "Tech",
+	"turret": "Turret",
+	"torpedo": "Torpedo",
+	"missile": "Missile",
+	"cannon": "Cannon",
+	"bomb": "Bomb",
+	"illicit": "Illicit",
+	"cargo": "Cargo",
+	"hardpoint": "Hardpoint",
+	"team": "Team",
+	"title": "Title",
+	"mod": "Modification",
+}
+
+func FactionMap(faction string) (string,error) {
+
+	faction = strings.ToLower(faction)
+	
+	switch faction {
+	case "rebel": fallthrough
+	case "rebel alliance": fallthrough
+	case "resistance":
+		return "rebel",nil
+		
+	case "imperial": fallthrough
+	case "galactic empire": fallthrough
+	case "first order":
+		return "imperial",nil
+
+	case "scum": fallthrough
+	case "scum and villainy":
+		return "scum",nil		
+	}
+
+	return "", fmt.Errorf("Unknown faction %v", faction)
+		
+}
+
+func (x *Exceptions) ShipMap(faction string, ship string) (string,error) {
+
+	// BEGIN EXCEPTIONS
+	ship = strings.ToLower(ship)
+
+	for _,r := range(x.ShipReplacements) {
+		ship = strings.Replace(ship, r.From, r.To, -1)
+	}
+
+	if alias,ok := x.ShipAliases[ship]; ok {
+		ship = alias
+	}
+	// END EXCEPTIONS
+	
+	f,err := FactionMap(faction)
+	return f + "/" + ship,err
+
+}
+
+func (x *Exceptions) PilotMap(faction string, ship string, pilot string) (string,error) {
+
+	// BEGIN EXCEPTIONS
+	pilot = strings.ToLower(pilot)
+
+	if alias,ok := x.PilotAliases[pilot]; ok {
+		pilot = alias
+	}
+
+	if s,ok := x.PilotShips[pilot]; ok {
+		ship = s.XWS
+	}
+	// END EXCEPTIONS
+	
+	s,err := x.ShipMap(faction,ship)
+	return s + "/" + pilot,err
+
+}
+
+func UpgradeMap(slot string, upgrade string) string {
+	return strings.ToLower(slot) + "/" + strings.ToLower(upgrade)
+}
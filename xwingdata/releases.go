@@ -0,0 +1,234 @@
+package xwingdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+)
+
+//
+// X-Wing Data lists the products each ship and pilot came in and when
+// they were released.  A card is available from the earliest release
+// that includes it.  Cards the data misses or gets wrong are corrected
+// by the overrides kept in ReleasesFile.
+//
+
+const ProductsURL = "https://github.com/guidokessels/xwing-data/raw/master/data/sources.js"
+
+const ReleasesFile = "releases.json"
+const ReleasesVersion = 1
+
+// Product is one of X-Wing Data's sources: an expansion, core set, or
+// other product, and the cards it contains by their X-Wing Data IDs.
+type Product struct {
+	Name string
+	ID int
+	Released bool
+	ReleaseDate string `json:"release_date"`
+	Contents struct {
+		Ships map[string]int
+		Pilots map[string]int
+		Upgrades map[string]int
+	}
+}
+
+// Release is when a card first became available, and in what product.
+type Release struct {
+	Date string
+	Product string
+}
+
+// Available reports whether the release date is known.
+func (r *Release) Available() bool {
+	return r.Date != ""
+}
+
+// ReleaseOverrides give the release of ships by their XWS code and of
+// pilots by their faction/ship/pilot code, replacing what is derived
+// from X-Wing Data.
+type ReleaseOverrides struct {
+	Version int
+	Ships map[string]Release
+	Pilots map[string]Release
+}
+
+func LoadReleases(file string, parent *logberry.Task) (*ReleaseOverrides,error) {
+
+	task := parent.Task("Load release overrides", logberry.D{"File": file})
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+	defer f.Close()
+
+	var x ReleaseOverrides
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&x)
+	if err != nil {
+		return nil,task.WrapError("Could not unmarshal release overrides", err)
+	}
+
+	err = x.validate()
+	if err != nil {
+		return nil,task.WrapError("Invalid release overrides", err)
+	}
+
+	return &x,task.Success(logberry.D{"Version": x.Version, "Ships": len(x.Ships), "Pilots": len(x.Pilots)})
+
+}
+
+func (x *ReleaseOverrides) validate() error {
+
+	if x.Version != ReleasesVersion {
+		return fmt.Errorf("Unsupported version %v, expected %v", x.Version, ReleasesVersion)
+	}
+
+	for _,m := range([]map[string]Release{x.Ships, x.Pilots}) {
+		for code,r := range(m) {
+			if _,err := time.Parse("2006-01-02", r.Date); err != nil {
+				return fmt.Errorf("Release of %v has bad date %v", code, r.Date)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// HasReleases reports whether any ship or pilot has a known release.
+func (d *Data) HasReleases() bool {
+	for _,pilot := range(d.Pilots) {
+		if pilot.Release.Available() {
+			return true
+		}
+	}
+	for _,ship := range(d.Ships) {
+		if ship.Release.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+// earliest keeps the earlier of a card's known release and another.
+func earliest(r *Release, date string, product string) {
+	if !r.Available() || date < r.Date {
+		r.Date = date
+		r.Product = product
+	}
+}
+
+func (d *Data) getreleases(url string, overrides *ReleaseOverrides, snapshot *fetch.Snapshot, parent *logberry.Task) error {
+
+	task := parent.Task("Get releases")
+
+	if url != "" && snapshot != nil && !snapshot.Saving() && !snapshot.Has(path.Base(url)) {
+		task.Warning("Snapshot has no products", logberry.D{"File": path.Base(url)})
+		url = ""
+	}
+
+	if url != "" {
+		err := fetch.GetAsJSON(&d.Products, url, snapshot, task)
+		if err != nil {
+			return task.Error(err)
+		}
+	}
+
+	ships := make(map[string]*Ship)
+	for _,ship := range(d.Ships) {
+		// Ships added by the exceptions aren't in X-Wing Data
+		if !containsship(d.Exceptions.Ships, ship) {
+			ships[fmt.Sprint(ship.ID)] = ship
+		}
+	}
+
+	pilots := make(map[string]*Pilot)
+	for _,pilot := range(d.Pilots) {
+		pilots[fmt.Sprint(pilot.ID)] = pilot
+	}
+
+	released := 0
+	for _,product := range(d.Products) {
+
+		if !product.Released || product.ReleaseDate == "" {
+			continue
+		}
+
+		if _,err := time.Parse("2006-01-02", product.ReleaseDate); err != nil {
+			task.Warning("Bad product release date", logberry.D{"Product": product.Name, "Date": product.ReleaseDate})
+			continue
+		}
+		released++
+
+		for id := range(product.Contents.Ships) {
+			if ship,ok := ships[id]; ok {
+				earliest(&ship.Release, product.ReleaseDate, product.Name)
+			}
+		}
+
+		for id := range(product.Contents.Pilots) {
+			if pilot,ok := pilots[id]; ok {
+				earliest(&pilot.Release, product.ReleaseDate, product.Name)
+			}
+		}
+
+	}
+
+	// BEGIN EXCEPTIONS
+	if overrides != nil {
+		for code,r := range(overrides.Pilots) {
+			pilot,ok := d.PilotsXWS[code]
+			if !ok {
+				task.Warning("Unknown pilot release override", logberry.D{"Code": code})
+				continue
+			}
+			pilot.Release = r
+		}
+
+		for xws,r := range(overrides.Ships) {
+			found := false
+			for _,ship := range(d.Ships) {
+				if ship.XWS == xws {
+					ship.Release = r
+					found = true
+				}
+			}
+			if !found {
+				task.Warning("Unknown ship release override", logberry.D{"XWS": xws})
+			}
+		}
+	}
+	// END EXCEPTIONS
+
+	// Ships no product lists, such as those added by the exceptions,
+	// are available once one of their pilots is
+	missing := make(map[*Ship]bool)
+	for _,ship := range(d.Ships) {
+		missing[ship] = !ship.Release.Available()
+	}
+	for _,pilot := range(d.Pilots) {
+		if missing[pilot.Chassis] && pilot.Release.Available() {
+			earliest(&pilot.Chassis.Release, pilot.Release.Date, pilot.Release.Product)
+		}
+	}
+
+	return task.Success(logberry.D{"Products": len(d.Products), "Released": released})
+
+}
+
+func containsship(list []*Ship, ship *Ship) bool {
+	for _,s := range(list) {
+		if s == ship {
+			return true
+		}
+	}
+	return false
+}
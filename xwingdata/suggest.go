@@ -0,0 +1,179 @@
+package xwingdata
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//
+// When a list names a pilot or ship that can't be matched, the closest
+// known codes are suggested as candidate aliases.  Codes are compared
+// after normalizing away case and punctuation, scoring by edit
+// distance, with abbreviations such as "ltlorrir" for
+// "lieutenantlorrir" scored by how much of the longer code they cover.
+//
+
+// Candidate is a known code suggested for an unknown one, with a
+// score from 0 to 1.
+type Candidate struct {
+	Code string
+	XWS string
+	Score float64
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	for _,r := range(strings.ToLower(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func minimum(values ...int) int {
+	m := values[0]
+	for _,v := range(values[1:]) {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func levenshtein(a string, b string) int {
+
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range(previous) {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+
+}
+
+// subsequence reports whether the runes of short appear in order in long.
+func subsequence(short string, long string) bool {
+	rs := []rune(short)
+	i := 0
+	for _,r := range(long) {
+		if i < len(rs) && rs[i] == r {
+			i++
+		}
+	}
+	return i == len(rs)
+}
+
+// Similarity scores how likely two codes are to name the same thing.
+func Similarity(a string, b string) float64 {
+
+	a, b = normalize(a), normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	short, long := a, b
+	if len([]rune(short)) > len([]rune(long)) {
+		short, long = long, short
+	}
+	ls, ll := float64(len([]rune(short))), float64(len([]rune(long)))
+
+	score := 1 - float64(levenshtein(a, b))/ll
+
+	// Abbreviations keep their first letter and the rest in order
+	if []rune(short)[0] == []rune(long)[0] && subsequence(short, long) {
+		if s := 0.5 + 0.5*ls/ll; s > score {
+			score = s
+		}
+	}
+
+	return score
+
+}
+
+func rank(candidates []Candidate, limit int) []Candidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Code < candidates[j].Code
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+func best(code string, names ...string) float64 {
+	score := 0.0
+	for _,name := range(names) {
+		if s := Similarity(code, name); s > score {
+			score = s
+		}
+	}
+	return score
+}
+
+// SuggestShips returns up to limit ships of a faction, given by its
+// code, closest to an unknown ship code, best first.
+func (d *Data) SuggestShips(faction string, ship string, limit int, threshold float64) []Candidate {
+
+	prefix := faction + "/"
+
+	var candidates []Candidate
+	for code,s := range(d.ShipsXWS) {
+		if !strings.HasPrefix(code, prefix) {
+			continue
+		}
+		score := best(ship, s.XWS, s.Name)
+		if score >= threshold {
+			candidates = append(candidates, Candidate{Code: code, XWS: s.XWS, Score: score})
+		}
+	}
+
+	return rank(candidates, limit)
+
+}
+
+// SuggestPilots returns up to limit pilots closest to an unknown pilot
+// code, best first.  Only pilots flying the ship with the given code
+// are considered, or of the same faction if the ship is unknown too.
+func (d *Data) SuggestPilots(ship string, pilot string, limit int, threshold float64) []Candidate {
+
+	prefix := ship + "/"
+	if _,ok := d.ShipsXWS[ship]; !ok {
+		prefix = strings.SplitN(ship, "/", 2)[0] + "/"
+	}
+
+	var candidates []Candidate
+	for code,p := range(d.PilotsXWS) {
+		if !strings.HasPrefix(code, prefix) {
+			continue
+		}
+		score := best(pilot, p.XWS, p.Name)
+		if score >= threshold {
+			candidates = append(candidates, Candidate{Code: code, XWS: p.XWS, Score: score})
+		}
+	}
+
+	return rank(candidates, limit)
+
+}
@@ -0,0 +1,126 @@
+package xwingdata
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+
+	cases := []struct {
+		a, b string
+		expected float64
+	}{
+		{"wedgeantilles", "wedgeantilles", 1},
+		{"Wedge Antilles", "wedgeantilles", 1},
+		{"", "wedgeantilles", 0},
+		{"!!", "wedgeantilles", 0},
+		{"biggs", "wedge", 1 - 4.0/5},
+		{"xwing", "ywing", 1 - 1.0/5},
+		{"lukeskywalkr", "lukeskywalker", 0.5 + 0.5*12/13},
+		{"lukeskywalker", "lukeskywalkr", 0.5 + 0.5*12/13},
+		{"ltlorrir", "lieutenantlorrir", 0.5 + 0.5*8/16},
+		{"lorrir", "lieutenantlorrir", 0.5 + 0.5*6/16},
+		{"tlorrir", "lieutenantlorrir", 1 - 9.0/16},
+	}
+
+	for _,c := range(cases) {
+		if s := Similarity(c.a, c.b); math.Abs(s - c.expected) > 1e-9 {
+			t.Errorf("Similarity of %q and %q is %v, expected %v", c.a, c.b, s, c.expected)
+		}
+	}
+
+}
+
+func testdata() *Data {
+
+	d := &Data{
+		ShipsXWS: make(map[string]*Ship),
+		PilotsXWS: make(map[string]*Pilot),
+	}
+
+	ships := []*Ship{
+		{Name: "X-Wing", XWS: "xwing", Faction: []string{"Rebel Alliance"}},
+		{Name: "Y-Wing", XWS: "ywing", Faction: []string{"Rebel Alliance", "Scum and Villainy"}},
+		{Name: "TIE Fighter", XWS: "tiefighter", Faction: []string{"Galactic Empire"}},
+	}
+	for _,ship := range(ships) {
+		for _,faction := range(ship.Faction) {
+			f,_ := FactionMap(faction)
+			d.ShipsXWS[f + "/" + ship.XWS] = ship
+		}
+	}
+
+	pilots := []struct {
+		code string
+		name string
+	}{
+		{"rebel/xwing/lukeskywalker", "Luke Skywalker"},
+		{"rebel/xwing/wedgeantilles", "Wedge Antilles"},
+		{"rebel/xwing/rookiepilot", "Rookie Pilot"},
+		{"rebel/ywing/hortonsalm", "Horton Salm"},
+		{"imperial/tiefighter/wedgeantilles", "Wedge Antilles"},
+	}
+	for _,p := range(pilots) {
+		d.PilotsXWS[p.code] = &Pilot{Name: p.name, XWS: p.code[strings.LastIndex(p.code, "/")+1:], Code: p.code}
+	}
+
+	return d
+
+}
+
+func TestSuggest(t *testing.T) {
+
+	d := testdata()
+
+	codes := func(candidates []Candidate) []string {
+		var list []string
+		for _,c := range(candidates) {
+			list = append(list, c.Code)
+		}
+		return list
+	}
+
+	cases := []struct {
+		name string
+		suggest func() []Candidate
+		expected []string
+	}{
+		{"Misspelled pilot", func() []Candidate { return d.SuggestPilots("rebel/xwing", "wedgeantiles", 3, 0.5) },
+			[]string{"rebel/xwing/wedgeantilles"}},
+		{"Pilot by name", func() []Candidate { return d.SuggestPilots("rebel/xwing", "Luke Skywalker", 3, 0.5) },
+			[]string{"rebel/xwing/lukeskywalker"}},
+		{"Unknown ship falls back to the faction", func() []Candidate { return d.SuggestPilots("rebel/awing", "hortonsalm", 3, 0.5) },
+			[]string{"rebel/ywing/hortonsalm"}},
+		{"Other factions left out", func() []Candidate { return d.SuggestPilots("imperial/tiefighter", "wedge", 3, 0.5) },
+			[]string{"imperial/tiefighter/wedgeantilles"}},
+		{"Nothing above the threshold", func() []Candidate { return d.SuggestPilots("rebel/xwing", "darthvader", 3, 0.5) },
+			nil},
+		{"Ties ranked by code", func() []Candidate { return d.SuggestShips("rebel", "awing", 3, 0.5) },
+			[]string{"rebel/xwing", "rebel/ywing"}},
+		{"Limited", func() []Candidate { return d.SuggestShips("rebel", "awing", 1, 0.5) },
+			[]string{"rebel/xwing"}},
+	}
+
+	for _,c := range(cases) {
+		candidates := c.suggest()
+		got := codes(candidates)
+		if len(got) != len(c.expected) {
+			t.Errorf("%v: suggested %v, expected %v", c.name, got, c.expected)
+			continue
+		}
+		for i := range(got) {
+			if got[i] != c.expected[i] {
+				t.Errorf("%v: suggested %v, expected %v", c.name, got, c.expected)
+				break
+			}
+		}
+		for i := 1; i < len(candidates); i++ {
+			if candidates[i].Score > candidates[i-1].Score {
+				t.Errorf("%v: suggestions %v aren't best first", c.name, candidates)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,326 @@
+// Package xwingdata models the ships, pilots, and upgrades published by
+// X-Wing Data and indexes them by their XWS codes.
+package xwingdata
+
+import (
+	"strconv"
+
+	"github.com/BellerophonMobile/logberry"
+
+	"github.com/RocketshipGames/xwing-csv/fetch"
+)
+
+const ShipStatsURL = "https://github.com/guidokessels/xwing-data/raw/master/data/ships.js"
+const PilotStatsURL = "https://github.com/guidokessels/xwing-data/raw/master/data/pilots.js"
+const UpgradeStatsURL = "https://github.com/guidokessels/xwing-data/raw/master/data/upgrades.js"
+
+// Sources gives the URLs to load X-Wing Data from.  Products are
+// optional, without them no releases are known.
+type Sources struct {
+	Ships string
+	Pilots string
+	Upgrades string
+	Products string
+}
+
+var DefaultSources = Sources{
+	Ships: ShipStatsURL,
+	Pilots: PilotStatsURL,
+	Upgrades: UpgradeStatsURL,
+	Products: ProductsURL,
+}
+
+// Int reads numeric fields that X-Wing Data sometimes fills with
+// strings such as "?", which become -1.
+type Int int
+
+func (i *Int) UnmarshalJSON(data []byte) error {
+
+	if v, err := strconv.Atoi(string(data)); err == nil {
+		(*i) = Int(v)
+		return nil
+	}
+
+	(*i) = -1
+
+	return nil
+	
+}
+
+type Ship struct {
+	Name string
+	Faction []string
+	Attack int
+	Agility int
+	Hull int
+	Shields int
+	Actions []string
+	Maneuvers [][]int
+	Size string
+	XWS string
+	ID int
+
+	// Resolved when loaded
+	Release Release `json:"-"`
+}
+
+// Factions returns the ship's factions in their short form, without
+// duplicates.
+func (s *Ship) Factions() ([]string,error) {
+	var factions []string
+	for _,x := range(s.Faction) {
+		fx,err := FactionMap(x)
+		if err != nil {
+			return nil,err
+		}
+		if !Contains(factions, fx) {
+			factions = append(factions, fx)
+		}
+	}
+	return factions,nil
+}
+
+type Pilot struct {
+	Name string
+	Unique bool
+	Ship string
+	Skill Int
+	Points Int
+	Slots []string
+	Text string
+	Image string
+	Faction string
+	XWS string
+	ID int
+
+	// Resolved when loaded
+	Chassis *Ship `json:"-"`
+	Code string `json:"-"`
+	Release Release `json:"-"`
+}
+
+type Upgrade struct {
+	Name string
+	Slot string
+	Points Int
+	Unique bool
+	Limited bool
+	Faction string
+	Ship []string
+	Size []string
+	Text string
+	XWS string
+
+	// Resolved when loaded, empty for the unreachable side of a
+	// dual-sided card
+	Code string `json:"-"`
+}
+
+// Data holds everything loaded from X-Wing Data, indexed for matching
+// against lists.
+type Data struct {
+	Exceptions *Exceptions
+
+	Ships []*Ship
+	ShipsXWS map[string]*Ship
+	ShipsGK map[string]*Ship
+
+	Pilots []*Pilot
+	PilotsXWS map[string]*Pilot
+	PilotNames map[string][]*Pilot
+
+	Upgrades []*Upgrade
+	UpgradesXWS map[string]*Upgrade
+
+	Products []*Product
+}
+
+// Load fetches and indexes the ships, pilots, and upgrades, and
+// resolves when each ship and pilot was released, reading from or
+// recording into snapshot if it is not nil.  The release overrides may
+// be nil.
+func Load(sources Sources, exceptions *Exceptions, releases *ReleaseOverrides, snapshot *fetch.Snapshot, parent *logberry.Task) (*Data,error) {
+
+	task := parent.Task("Load X-Wing Data")
+
+	d := &Data{
+		Exceptions: exceptions,
+		ShipsXWS: make(map[string]*Ship),
+		ShipsGK: make(map[string]*Ship),
+		PilotsXWS: make(map[string]*Pilot),
+		PilotNames: make(map[string][]*Pilot),
+		UpgradesXWS: make(map[string]*Upgrade),
+	}
+
+	err := d.getshipstats(sources.Ships, snapshot, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+	
+	err = d.getpilotstats(sources.Pilots, snapshot, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	err = d.getupgradestats(sources.Upgrades, snapshot, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	err = d.getreleases(sources.Products, releases, snapshot, task)
+	if err != nil {
+		return nil,task.Error(err)
+	}
+
+	return d,task.Success()
+
+}
+
+func (d *Data) getshipstats(url string, snapshot *fetch.Snapshot, parent *logberry.Task) error {
+
+	task := parent.Task("Get ship stats")
+
+	err := fetch.GetAsJSON(&d.Ships, url, snapshot, task)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	// BEGIN EXCEPTIONS
+	d.Ships = append(d.Ships, d.Exceptions.Ships...)
+	// END EXCEPTIONS
+	
+	// Process each ship
+	for _,ship := range(d.Ships) {
+
+		factions,err := ship.Factions()
+		if err != nil {
+			return task.Error(err, ship)
+		}
+		
+		for _,faction := range(factions) {
+			code,err := d.Exceptions.ShipMap(faction, ship.XWS)
+			if err != nil {
+				return task.Error(err)
+			}
+			if s,ok := d.ShipsXWS[code]; ok {
+				return task.Failure("Duplicate ship XWS", logberry.D{"Code": code, "New": ship, "Existing": s})
+			}
+			d.ShipsXWS[code] = ship
+
+			code,err = d.Exceptions.ShipMap(faction, ship.Name)
+			if err != nil {
+				return task.Error(err)
+			}
+			if s,ok := d.ShipsGK[code]; ok {
+				return task.Failure("Duplicate ship name", logberry.D{"Code": code, "New": ship, "Existing": s})
+			}
+			d.ShipsGK[code] = ship
+
+		}
+
+	}
+	
+	return task.Success(logberry.D{"Ships": len(d.Ships)})
+
+}
+
+func (d *Data) getpilotstats(url string, snapshot *fetch.Snapshot, parent *logberry.Task) error {
+
+	task := parent.Task("Get pilot stats")
+	
+	err := fetch.GetAsJSON(&d.Pilots, url, snapshot, task)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	for _,pilot := range(d.Pilots) {
+
+		shipgkcode,err := d.Exceptions.ShipMap(pilot.Faction, pilot.Ship)
+		if err != nil {
+			return task.Error(err)
+		}			
+
+		// BEGIN EXCEPTIONS
+		if s,ok := d.Exceptions.PilotShips[pilot.XWS]; ok {
+			pilot.Ship = s.Name
+			shipgkcode,err = d.Exceptions.ShipMap(pilot.Faction, s.Name)
+			if err != nil {
+				return task.Error(err)
+			}	
+		}
+		// END EXCEPTIONS
+		
+		ship,ok := d.ShipsGK[shipgkcode]
+		if !ok {
+			return task.Failure("Pilot has no ship", logberry.D{"Pilot": pilot, "ShipGKCode": shipgkcode})
+		}
+
+		pilot.Chassis = ship
+
+		xws,err := d.Exceptions.PilotMap(pilot.Faction, ship.XWS, pilot.XWS)
+		if err != nil {
+			return task.Error(err)
+		}			
+
+		if p,ok := d.PilotsXWS[xws]; ok {
+			return task.Failure("Duplicate pilot XWS", logberry.D{"XWS": xws, "New": pilot, "Existing": p})
+		}
+		d.PilotsXWS[xws] = pilot
+		pilot.Code = xws
+		d.PilotNames[pilot.Name] = append(d.PilotNames[pilot.Name], pilot)
+	}
+
+	return task.Success(logberry.D{"Pilots": len(d.Pilots)})
+	
+}
+
+func (d *Data) getupgradestats(url string, snapshot *fetch.Snapshot, parent *logberry.Task) error {
+
+	task := parent.Task("Get upgrade stats")
+	
+	err := fetch.GetAsJSON(&d.Upgrades, url, snapshot, task)
+	if err != nil {
+		return task.Error(err)
+	}
+
+	for _,upgrade := range(d.Upgrades) {
+
+		xws := UpgradeMap(upgrade.Slot, upgrade.XWS)
+
+		// Dual-sided cards share an XWS code, so only the first side is
+		// resolvable from a list
+		if u,ok := d.UpgradesXWS[xws]; ok {
+			task.Warning("Duplicate upgrade XWS", logberry.D{"XWS": xws, "New": upgrade.Name, "Existing": u.Name})
+			continue
+		}
+		d.UpgradesXWS[xws] = upgrade
+		upgrade.Code = xws
+	}
+
+	return task.Success(logberry.D{"Upgrades": len(d.Upgrades)})
+	
+}
+
+// PilotLabel names a pilot for display, disambiguating pilots that
+// share a name.
+func (d *Data) PilotLabel(pilot *Pilot) string {
+
+	label := pilot.Name
+
+	// BEGIN EXCEPTIONS
+	//
+	// This is a bit of a mess because the duplicates have slightly
+	// different problems.  Some have different XWS but same ship name,
+	// while others duplicate XWS but different ships.
+	if len(d.PilotNames[pilot.Name]) > 1 {
+		if Contains(d.Exceptions.FactionLabels, pilot.Name) {
+			label = label + " (" + pilot.Faction + ")"			
+		} else {
+			label = label + " (" + pilot.Ship + ")"
+		}
+	}
+	// END EXCEPTIONS
+
+	return label
+	
+}